@@ -6,25 +6,51 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var addPath string
+var (
+	addPath          string
+	addCD            bool
+	addKeepOnFailure bool
+	addFetch         bool
+	addFrom          string
+)
 
 var addCmd = &cobra.Command{
 	Use:   "add <branch>",
 	Short: "Create a new worktree",
-	Long:  "Create a new git worktree with file sync and optional background tasks.",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runAdd,
+	Long: `Create a new git worktree with file sync and optional background tasks.
+
+If the branch does not exist locally but exists on one of worktree.remotes
+(default: origin), a local branch tracking the remote one is created.
+Otherwise wm offers to create a new branch starting at --from, at
+worktree.default_base, or at the main worktree's HEAD.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAdd,
 }
 
 func init() {
 	addCmd.Flags().StringVarP(&addPath, "path", "p", "", "Custom path for the worktree")
+	addCmd.Flags().BoolVar(&addCD, "cd", false, "Change into the new worktree (requires shell integration)")
+	addCmd.Flags().BoolVar(&addKeepOnFailure, "keep-on-failure", false, "Keep the worktree if sync or setup fails")
+	addCmd.Flags().BoolVar(&addFetch, "fetch", false, "Fetch the configured remotes before looking for the branch")
+	addCmd.Flags().StringVar(&addFrom, "from", "", "Start a new branch at this ref, e.g. origin/main")
 	rootCmd.AddCommand(addCmd)
 }
 
 func runAdd(cmd *cobra.Command, args []string) error {
-	ws, err := workspace.Open(ui.NewConsole())
+	console := ui.NewConsole()
+	ws, err := workspace.Open(console)
 	if err != nil {
 		return err
 	}
-	return ws.AddWorktree(args[0], addPath)
+
+	wtPath, err := ws.AddWorktree(args[0], workspace.AddOptions{
+		Path:          addPath,
+		KeepOnFailure: addKeepOnFailure,
+		Fetch:         addFetch,
+		From:          addFrom,
+	})
+	if err != nil || wtPath == "" {
+		return err
+	}
+	return changeDir(console, wtPath, addCD)
 }
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"github.com/Devdha/wm/internal/ui"
+	"github.com/Devdha/wm/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var doctorForce bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Find and repair broken sync symlinks",
+	Long: `Check every worktree for symlinks created by 'mode: symlink' sync items
+whose target no longer exists, e.g. after the repository or the worktrees
+were moved, and relink them to their source after confirmation.`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	doctorCmd.Flags().BoolVarP(&doctorForce, "force", "f", false, "Repair without confirmation")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	ws, err := workspace.Open(ui.NewConsole())
+	if err != nil {
+		return err
+	}
+	return ws.Doctor(doctorForce)
+}
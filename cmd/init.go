@@ -96,7 +96,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 	if installCmd != "" {
 		cfg.Tasks.PostInstall = config.PostInstallConfig{
 			Mode:     "background",
-			Commands: []string{installCmd},
+			Commands: []config.Command{{Run: installCmd}},
 		}
 	}
 
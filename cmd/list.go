@@ -5,6 +5,7 @@ import (
 	"os"
 	"text/tabwriter"
 
+	"github.com/Devdha/wm/internal/git"
 	"github.com/Devdha/wm/internal/ui"
 	"github.com/Devdha/wm/internal/workspace"
 	"github.com/spf13/cobra"
@@ -14,11 +15,48 @@ var listCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
 	Short:   "List all worktrees",
-	Long:    "List all git worktrees in the current repository with their branches and status.",
-	RunE:    runList,
+	Long: `List all git worktrees in the current repository with their branches and status.
+
+With --json or --format each worktree also reports whether it is bare,
+detached, locked or prunable.`,
+	RunE: runList,
+}
+
+// worktreeInfo is one worktree in list and status output
+type worktreeInfo struct {
+	Path           string `json:"path"`
+	Branch         string `json:"branch"` // Empty when detached
+	HEAD           string `json:"head"`
+	Bare           bool   `json:"bare"`
+	Detached       bool   `json:"detached"`
+	Locked         bool   `json:"locked"`
+	LockReason     string `json:"lock_reason,omitempty"`
+	Prunable       bool   `json:"prunable"`
+	PrunableReason string `json:"prunable_reason,omitempty"`
+	Ports          []int  `json:"ports"` // Reserved ports; empty if none
+}
+
+func newWorktreeInfo(ws *workspace.Workspace, wt git.Worktree) worktreeInfo {
+	info := worktreeInfo{
+		Path:           wt.Path,
+		Branch:         wt.Branch,
+		HEAD:           wt.HEAD,
+		Bare:           wt.Bare,
+		Detached:       wt.Detached,
+		Locked:         wt.Locked,
+		LockReason:     wt.LockReason,
+		Prunable:       wt.Prunable,
+		PrunableReason: wt.PrunableReason,
+		Ports:          []int{},
+	}
+	if block, ok := ws.Ports(wt.Path); ok {
+		info.Ports = block.Ports()
+	}
+	return info
 }
 
 func init() {
+	addOutputFlags(listCmd)
 	rootCmd.AddCommand(listCmd)
 }
 
@@ -33,14 +71,22 @@ func runList(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if structuredOutput() {
+		infos := make([]worktreeInfo, len(worktrees))
+		for i, wt := range worktrees {
+			infos[i] = newWorktreeInfo(ws, wt)
+		}
+		return printItems("worktrees", infos)
+	}
+
 	if len(worktrees) == 0 {
 		fmt.Println("No worktrees found.")
 		return nil
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "PATH\tBRANCH\tHEAD")
-	fmt.Fprintln(w, "----\t------\t----")
+	fmt.Fprintln(w, "PATH\tBRANCH\tHEAD\tPORTS")
+	fmt.Fprintln(w, "----\t------\t----\t-----")
 
 	for _, wt := range worktrees {
 		branch := wt.Branch
@@ -51,7 +97,11 @@ func runList(cmd *cobra.Command, args []string) error {
 		if len(shortHead) > 7 {
 			shortHead = shortHead[:7]
 		}
-		fmt.Fprintf(w, "%s\t%s\t%s\n", wt.Path, branch, shortHead)
+		ports := "-"
+		if block, ok := ws.Ports(wt.Path); ok {
+			ports = block.String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", wt.Path, branch, shortHead, ports)
 	}
 
 	w.Flush()
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// schemaVersion is reported as "version" in --json output. It only changes
+// when fields are removed or change meaning; new fields may appear at any
+// time.
+const schemaVersion = 1
+
+var (
+	outputJSON   bool
+	outputFormat string
+)
+
+// addOutputFlags adds --json and --format to a read-only command
+func addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&outputJSON, "json", false, "Print JSON with a versioned schema")
+	cmd.Flags().StringVar(&outputFormat, "format", "", "Print each item with a Go template, e.g. '{{.Path}} {{.Branch}}'")
+	cmd.MarkFlagsMutuallyExclusive("json", "format")
+}
+
+// structuredOutput reports whether --json or --format was given
+func structuredOutput() bool {
+	return outputJSON || outputFormat != ""
+}
+
+// printItems writes items as {"version": N, key: [...]} with --json, or
+// each item on its own line through the --format template
+func printItems[T any](key string, items []T) error {
+	if items == nil {
+		items = []T{}
+	}
+	if outputJSON {
+		return printJSON(key, items)
+	}
+
+	tmpl, err := parseFormat()
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := executeFormat(tmpl, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printItem is printItems for commands that report a single object
+func printItem(key string, item any) error {
+	if outputJSON {
+		return printJSON(key, item)
+	}
+
+	tmpl, err := parseFormat()
+	if err != nil {
+		return err
+	}
+	return executeFormat(tmpl, item)
+}
+
+// printJSON writes {"version": N, key: value} with the version first
+func printJSON(key string, value any) error {
+	data, err := json.MarshalIndent(value, "  ", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal output: %w", err)
+	}
+	_, err = fmt.Fprintf(os.Stdout, "{\n  \"version\": %d,\n  \"%s\": %s\n}\n", schemaVersion, key, data)
+	return err
+}
+
+func parseFormat() (*template.Template, error) {
+	tmpl, err := template.New("format").Parse(outputFormat)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --format: %w", err)
+	}
+	return tmpl, nil
+}
+
+func executeFormat(tmpl *template.Template, item any) error {
+	if err := tmpl.Execute(os.Stdout, item); err != nil {
+		return fmt.Errorf("--format: %w", err)
+	}
+	fmt.Println()
+	return nil
+}
+
+// optionalTime returns nil for the zero time, so it is omitted from JSON
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
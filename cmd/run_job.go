@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Devdha/wm/internal/notify"
+	"github.com/Devdha/wm/internal/runner"
+	"github.com/spf13/cobra"
+)
+
+// runJobCmd is spawned by runner.StartJob to supervise a detached job
+var runJobCmd = &cobra.Command{
+	Use:           runner.RunJobCommand + " <state-dir> <name>",
+	Hidden:        true,
+	Args:          cobra.ExactArgs(2),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runJob,
+}
+
+func init() {
+	rootCmd.AddCommand(runJobCmd)
+}
+
+func runJob(cmd *cobra.Command, args []string) error {
+	stateDir, name := args[0], args[1]
+	jobErr := runner.RunJob(stateDir, name, os.Stdout)
+
+	job, err := runner.LoadJob(stateDir, name)
+	if err != nil {
+		return err
+	}
+
+	opts := notify.Options{Shell: job.Shell}
+	if tty := job.Terminal(); tty != nil {
+		defer tty.Close()
+		opts.Terminal = tty
+	}
+	if err := notify.Send(job.Notify, notify.FromJob(job), opts); err != nil {
+		// Output goes to the job log
+		fmt.Fprintf(os.Stderr, "wm: notification failed: %v\n", err)
+	}
+	return jobErr
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/Devdha/wm/internal/shell"
+	"github.com/spf13/cobra"
+)
+
+var shellInitCmd = &cobra.Command{
+	Use:   "shell-init <bash|zsh|fish>",
+	Short: "Print shell integration code",
+	Long: `Print a shell function that wraps wm so 'wm switch' and 'wm add --cd'
+can change the current directory.
+
+  bash:  echo 'eval "$(wm shell-init bash)"' >> ~/.bashrc
+  zsh:   echo 'eval "$(wm shell-init zsh)"' >> ~/.zshrc
+  fish:  echo 'wm shell-init fish | source' >> ~/.config/fish/config.fish`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: shell.Supported(),
+	RunE:      runShellInit,
+}
+
+func init() {
+	rootCmd.AddCommand(shellInitCmd)
+}
+
+func runShellInit(cmd *cobra.Command, args []string) error {
+	script, err := shell.Script(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Print(script)
+	return nil
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Devdha/wm/internal/git"
+	"github.com/Devdha/wm/internal/ui"
+	"github.com/Devdha/wm/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the state of every worktree",
+	Long: `Show local changes, ahead/behind counts against the upstream and the
+default base, the last commit, lock state and post-install task state of
+every worktree. The base is what wm add created the worktree from, or else
+worktree.default_base or the branch checked out in the main worktree.
+
+With --json or --format every count is reported as its own field.`,
+	Args: cobra.NoArgs,
+	RunE: runStatus,
+}
+
+// statusInfo is one worktree in status output
+type statusInfo struct {
+	worktreeInfo
+	Dirty       bool       `json:"dirty"`
+	Staged      int        `json:"staged"`
+	Unstaged    int        `json:"unstaged"`
+	Untracked   int        `json:"untracked"`
+	Conflicted  int        `json:"conflicted"`
+	Upstream    string     `json:"upstream,omitempty"`
+	Ahead       int        `json:"ahead"`  // Commits not pushed to Upstream
+	Behind      int        `json:"behind"` // Upstream commits not merged
+	Base        string     `json:"base,omitempty"`
+	BaseCommit  string     `json:"base_commit,omitempty"`
+	BaseAhead   int        `json:"base_ahead"`
+	BaseBehind  int        `json:"base_behind"`
+	LastCommit  string     `json:"last_commit,omitempty"` // Subject
+	CommittedAt *time.Time `json:"committed_at,omitempty"`
+	Tasks       string     `json:"tasks,omitempty"` // Post-install state
+	Error       string     `json:"error,omitempty"` // Why the worktree could not be inspected
+}
+
+func newStatusInfo(ws *workspace.Workspace, s workspace.WorktreeStatus) statusInfo {
+	info := statusInfo{
+		worktreeInfo: newWorktreeInfo(ws, s.Worktree),
+		BaseAhead:    s.BaseAhead,
+		BaseBehind:   s.BaseBehind,
+		LastCommit:   s.Subject,
+		CommittedAt:  optionalTime(s.CommittedAt),
+		Tasks:        s.Tasks,
+	}
+	if s.Base != nil {
+		info.Base, info.BaseCommit = s.Base.Ref, s.Base.Commit
+	}
+	if s.Err != nil {
+		info.Error = s.Err.Error()
+	}
+	if g := s.Git; g != nil {
+		info.Dirty = g.Dirty()
+		info.Staged, info.Unstaged, info.Untracked, info.Conflicted = g.Staged, g.Unstaged, g.Untracked, g.Conflicted
+		info.Upstream, info.Ahead, info.Behind = g.Upstream, g.Ahead, g.Behind
+	}
+	return info
+}
+
+func init() {
+	addOutputFlags(statusCmd)
+	rootCmd.AddCommand(statusCmd)
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	ws, err := workspace.Open(ui.NewSilent(false))
+	if err != nil {
+		return err
+	}
+
+	statuses, err := ws.Status()
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		infos := make([]statusInfo, len(statuses))
+		for i, st := range statuses {
+			infos[i] = newStatusInfo(ws, st)
+		}
+		return printItems("worktrees", infos)
+	}
+
+	if len(statuses) == 0 {
+		fmt.Println("No worktrees found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PATH\tBRANCH\tCHANGES\tUPSTREAM\tBASE\tLAST COMMIT\tTASKS")
+	fmt.Fprintln(w, "----\t------\t-------\t--------\t----\t-----------\t-----")
+
+	now := time.Now()
+	for _, s := range statuses {
+		path := s.Path
+		if s.Locked {
+			path += " (locked)"
+		}
+		branch := s.Branch
+		if branch == "" {
+			branch = "(detached)"
+		}
+		if s.Err != nil {
+			fmt.Fprintf(w, "%s\t%s\t%s\t\t\t\t\n", path, branch, s.Err)
+			continue
+		}
+
+		base := "-"
+		if s.Base != nil {
+			ref := s.Base.Ref
+			if ref == "HEAD" {
+				ref = s.Base.String() // A detached HEAD says little without its commit
+			}
+			base = fmt.Sprintf("%s %s", ref, aheadBehind(s.BaseAhead, s.BaseBehind))
+		}
+		commit := "-"
+		if !s.CommittedAt.IsZero() {
+			commit = fmt.Sprintf("%s (%s)", truncate(s.Subject, 40), formatAge(now.Sub(s.CommittedAt)))
+		}
+		tasks := s.Tasks
+		if tasks == "" {
+			tasks = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			path, branch, changeSummary(s.Git), upstreamSummary(s.Git), base, commit, tasks)
+	}
+
+	w.Flush()
+	return nil
+}
+
+// changeSummary formats local changes like "2 staged, 1 unstaged"
+func changeSummary(s *git.Status) string {
+	if !s.Dirty() {
+		return "clean"
+	}
+	var parts []string
+	for _, c := range []struct {
+		n    int
+		kind string
+	}{{s.Conflicted, "conflicted"}, {s.Staged, "staged"}, {s.Unstaged, "unstaged"}, {s.Untracked, "untracked"}} {
+		if c.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c.n, c.kind))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func upstreamSummary(s *git.Status) string {
+	if s.Upstream == "" {
+		return "none"
+	}
+	return fmt.Sprintf("%s %s", s.Upstream, aheadBehind(s.Ahead, s.Behind))
+}
+
+// aheadBehind formats commit counts like "+2/-1"
+func aheadBehind(ahead, behind int) string {
+	return fmt.Sprintf("+%d/-%d", ahead, behind)
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-3]) + "..."
+	}
+	return s
+}
+
+// formatAge formats a duration like "5m ago" or "3d ago"
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	case d < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%dmo ago", int(d.Hours()/24/30))
+	}
+	return fmt.Sprintf("%dy ago", int(d.Hours()/24/365))
+}
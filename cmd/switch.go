@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/Devdha/wm/internal/shell"
+	"github.com/Devdha/wm/internal/ui"
+	"github.com/Devdha/wm/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var switchCmd = &cobra.Command{
+	Use:     "switch <branch|path>",
+	Aliases: []string{"sw"},
+	Short:   "Change into a worktree",
+	Long: `Change into a worktree, matched by path, directory name or branch.
+
+With shell integration enabled (see 'wm shell-init') the current shell
+changes directory. Otherwise the worktree path is printed, so
+'cd "$(wm switch <branch>)"' works as well.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSwitch,
+}
+
+func init() {
+	rootCmd.AddCommand(switchCmd)
+}
+
+func runSwitch(cmd *cobra.Command, args []string) error {
+	ws, err := workspace.Open(ui.NewSilent(false))
+	if err != nil {
+		return err
+	}
+
+	wt, err := ws.FindWorktree(args[0])
+	if err != nil {
+		return err
+	}
+
+	handled, err := shell.RequestCD(wt.Path)
+	if err != nil {
+		return err
+	}
+	if !handled {
+		fmt.Println(wt.Path)
+	}
+	return nil
+}
+
+// changeDir hands wtPath to the shell wrapper when requested, falling back
+// to printing the cd command for the user to run.
+func changeDir(prompter ui.Prompter, wtPath string, requested bool) error {
+	if requested {
+		handled, err := shell.RequestCD(wtPath)
+		if err != nil {
+			return err
+		}
+		if handled {
+			return nil
+		}
+		prompter.Print("Shell integration is not enabled; see 'wm shell-init --help'.")
+	}
+	prompter.Printf("  cd %s\n", wtPath)
+	return nil
+}
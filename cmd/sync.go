@@ -0,0 +1,343 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Devdha/wm/internal/config"
+	"github.com/Devdha/wm/internal/git"
+	"github.com/Devdha/wm/internal/sync"
+	"github.com/Devdha/wm/internal/ui"
+	"github.com/Devdha/wm/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncAll      bool
+	syncWatch    bool
+	syncDebounce time.Duration
+	syncFrom     string
+	syncTo       string
+	syncForce    bool
+
+	syncStatusAll bool
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync [worktree...]",
+	Short: "Re-sync files into existing worktrees",
+	Long: `Copy the files listed under 'sync' in .wm.yaml from the repository root
+into existing worktrees, honouring each item's mode and when settings.
+
+Without arguments the current worktree is synced. With --watch, changes to
+the sync sources are propagated (to every worktree unless some are named)
+until interrupted.
+
+With --from the items are copied the other way, from a worktree into the
+repository root (or the worktree given by --to, or every other worktree
+with --all). The changes are shown as diffs and applied after confirmation.`,
+	RunE: runSync,
+}
+
+var syncStatusCmd = &cobra.Command{
+	Use:   "status [worktree...]",
+	Short: "Show whether synced files match their sources",
+	Long: `Compare the files synced into worktrees with their sources in the
+repository root. Each file is reported as in-sync, stale (the source changed
+since the last sync), modified (edited in the worktree), missing, dangling
+(a symlink whose target is gone) or no-source.
+
+Without arguments the current worktree is checked.`,
+	RunE: runSyncStatus,
+}
+
+func init() {
+	syncCmd.Flags().BoolVarP(&syncAll, "all", "a", false, "Sync every worktree")
+	syncCmd.Flags().BoolVarP(&syncWatch, "watch", "w", false, "Keep propagating changes to the worktrees")
+	syncCmd.Flags().DurationVar(&syncDebounce, "debounce", 300*time.Millisecond, "Wait this long after a change before syncing")
+	syncCmd.Flags().StringVar(&syncFrom, "from", "", "Copy the sync items from this worktree instead of the root")
+	syncCmd.Flags().StringVar(&syncTo, "to", "", "With --from: the worktree to copy into (default: the repository root)")
+	syncCmd.Flags().BoolVarP(&syncForce, "force", "f", false, "With --from: apply without confirmation")
+	addOutputFlags(syncStatusCmd)
+	syncStatusCmd.Flags().BoolVarP(&syncStatusAll, "all", "a", false, "Check every worktree")
+	syncCmd.AddCommand(syncStatusCmd)
+	rootCmd.AddCommand(syncCmd)
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	console := ui.NewConsole()
+	ws, err := workspace.Open(console)
+	if err != nil {
+		return err
+	}
+
+	if len(ws.Config.Sync) == 0 {
+		console.Printf("No sync items configured in %s.\n", config.ConfigFileName)
+		return nil
+	}
+
+	if syncFrom != "" {
+		return reverseSync(cmd, ws, args)
+	}
+
+	if syncWatch {
+		return watchSync(ws, args)
+	}
+
+	targets, err := syncTargets(ws, args, syncAll)
+	if err != nil {
+		return err
+	}
+
+	// One broken worktree should not keep the others stale
+	var errs []error
+	for _, wt := range targets {
+		if wt.Prunable {
+			console.Printf("Warning: skipping %s: %s\n", wt.Path, missingWorktree)
+			continue
+		}
+		console.Printf("%s:\n", wt.Path)
+		results, err := ws.SyncWorktree(wt.Path)
+		printSyncResults(console, results)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to sync %s: %w", wt.Path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func runSyncStatus(cmd *cobra.Command, args []string) error {
+	console := ui.NewConsole()
+	ws, err := workspace.Open(console)
+	if err != nil {
+		return err
+	}
+
+	if len(ws.Config.Sync) == 0 {
+		if structuredOutput() {
+			return printItems[syncFileInfo]("files", nil)
+		}
+		console.Printf("No sync items configured in %s.\n", config.ConfigFileName)
+		return nil
+	}
+
+	targets, err := syncTargets(ws, args, syncStatusAll)
+	if err != nil {
+		return err
+	}
+
+	var infos []syncFileInfo
+	for _, wt := range targets {
+		if wt.Prunable {
+			if structuredOutput() {
+				infos = append(infos, syncFileInfo{Worktree: wt.Path, Error: missingWorktree})
+			} else {
+				console.Printf("%s:\n  %s\n", wt.Path, missingWorktree)
+			}
+			continue
+		}
+
+		statuses, err := ws.SyncStatus(wt.Path)
+		if err != nil {
+			return fmt.Errorf("failed to check %s: %w", wt.Path, err)
+		}
+		if structuredOutput() {
+			for _, s := range statuses {
+				infos = append(infos, syncFileInfo{
+					Worktree: wt.Path,
+					Src:      filepath.ToSlash(s.Src),
+					Dst:      filepath.ToSlash(s.Dst),
+					Mode:     s.Mode,
+					State:    string(s.State),
+					SyncedAt: optionalTime(s.SyncedAt),
+				})
+			}
+			continue
+		}
+
+		console.Printf("%s:\n", wt.Path)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		counts := make(map[sync.State]int)
+		for _, s := range statuses {
+			path := s.Dst
+			if s.Src != s.Dst {
+				path = fmt.Sprintf("%s -> %s", s.Src, s.Dst)
+			}
+			synced := "never synced"
+			if !s.SyncedAt.IsZero() {
+				synced = "synced " + s.SyncedAt.Local().Format("2006-01-02 15:04")
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", s.State, s.Mode, path, synced)
+			counts[s.State]++
+		}
+		w.Flush()
+		console.Printf("  %s\n", stateSummary(counts))
+	}
+
+	if structuredOutput() {
+		return printItems("files", infos)
+	}
+	return nil
+}
+
+// syncFileInfo is one synced path in sync status output
+type syncFileInfo struct {
+	Worktree string     `json:"worktree"`
+	Src      string     `json:"src"` // Relative to the repository root
+	Dst      string     `json:"dst"` // Relative to the worktree
+	Mode     string     `json:"mode"`
+	State    string     `json:"state"`
+	SyncedAt *time.Time `json:"synced_at,omitempty"`
+	Error    string     `json:"error,omitempty"` // Set instead of the file fields when the worktree is missing
+}
+
+// stateSummary formats counts like "3 in-sync, 1 stale"
+func stateSummary(counts map[sync.State]int) string {
+	var parts []string
+	for _, state := range []sync.State{sync.StateInSync, sync.StateStale, sync.StateModified,
+		sync.StateMissing, sync.StateDangling, sync.StateNoSource} {
+		if counts[state] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[state], state))
+		}
+	}
+	if len(parts) == 0 {
+		return "no files"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func reverseSync(cmd *cobra.Command, ws *workspace.Workspace, args []string) error {
+	switch {
+	case len(args) > 0:
+		return fmt.Errorf("--from takes its destination from --to or --all, not arguments")
+	case syncWatch:
+		return fmt.Errorf("cannot combine --from with --watch")
+	case syncAll && cmd.Flags().Changed("to"):
+		return fmt.Errorf("cannot combine --to with --all")
+	}
+
+	src, err := ws.FindWorktree(syncFrom)
+	if err != nil {
+		return err
+	}
+
+	var dsts []string
+	switch {
+	case syncAll:
+		worktrees, err := ws.ListWorktrees()
+		if err != nil {
+			return err
+		}
+		for _, wt := range worktrees {
+			if wt.Path != src.Path && !wt.Bare && !wt.Prunable {
+				dsts = append(dsts, wt.Path)
+			}
+		}
+	case syncTo == "":
+		dsts = []string{ws.Root}
+	default:
+		dst, err := ws.FindWorktree(syncTo)
+		if err != nil {
+			return err
+		}
+		dsts = []string{dst.Path}
+	}
+
+	for _, dst := range dsts {
+		if dst == src.Path {
+			return fmt.Errorf("cannot sync %s into itself", src.Path)
+		}
+	}
+	return ws.SyncFrom(src.Path, dsts, syncForce)
+}
+
+func watchSync(ws *workspace.Workspace, args []string) error {
+	// Watching defaults to every worktree, re-resolved on each change so new
+	// worktrees are picked up
+	all := syncAll || len(args) == 0
+	targets := func() ([]git.Worktree, error) {
+		return syncTargets(ws, args, all)
+	}
+	if _, err := targets(); err != nil {
+		return err
+	}
+
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		close(stop)
+	}()
+
+	return ws.WatchSync(targets, syncDebounce, stop)
+}
+
+// syncTargets resolves the worktrees named on the command line, all linked
+// worktrees with --all, or the current one
+func syncTargets(ws *workspace.Workspace, args []string, all bool) ([]git.Worktree, error) {
+	if all {
+		if len(args) > 0 {
+			return nil, fmt.Errorf("cannot combine --all with worktree arguments")
+		}
+		worktrees, err := ws.ListWorktrees()
+		if err != nil {
+			return nil, err
+		}
+
+		var targets []git.Worktree
+		for _, wt := range worktrees {
+			if wt.Path != ws.Root && !wt.Bare {
+				targets = append(targets, wt)
+			}
+		}
+		return targets, nil
+	}
+
+	if len(args) == 0 {
+		wt, err := ws.CurrentWorktree()
+		if err != nil {
+			return nil, err
+		}
+		if wt.Path == ws.Root {
+			return nil, fmt.Errorf("the main worktree is the sync source; name a worktree or use --all")
+		}
+		return []git.Worktree{*wt}, nil
+	}
+
+	targets := make([]git.Worktree, 0, len(args))
+	for _, arg := range args {
+		wt, err := ws.FindWorktree(arg)
+		if err != nil {
+			return nil, err
+		}
+		if wt.Path == ws.Root {
+			return nil, fmt.Errorf("cannot sync into the main worktree")
+		}
+		targets = append(targets, *wt)
+	}
+	return targets, nil
+}
+
+func printSyncResults(prompter ui.Prompter, results []sync.Result) {
+	for _, r := range results {
+		path := r.Dst
+		if r.Src != r.Dst {
+			path = fmt.Sprintf("%s -> %s", r.Src, r.Dst)
+		}
+		if r.Backup != "" {
+			path += fmt.Sprintf(" (local copy saved as %s)", r.Backup)
+		}
+		prompter.Printf("  %-16s %s\n", r.Status, path)
+	}
+	if len(results) > 0 {
+		prompter.Printf("  %s\n", sync.Summarize(results))
+	}
+}
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Devdha/wm/internal/git"
+	"github.com/Devdha/wm/internal/runner"
+	"github.com/Devdha/wm/internal/ui"
+	"github.com/Devdha/wm/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var tasksFollow bool
+
+var tasksCmd = &cobra.Command{
+	Use:   "tasks [worktree]",
+	Short: "Show background post-install tasks",
+	Long:  "Show the state, exit code and duration of background post-install tasks for all worktrees or a single one.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runTasks,
+}
+
+var tasksLogsCmd = &cobra.Command{
+	Use:   "logs <worktree>",
+	Short: "Print the post-install log of a worktree",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTasksLogs,
+}
+
+var tasksKillCmd = &cobra.Command{
+	Use:   "kill <worktree>",
+	Short: "Stop running post-install tasks of a worktree",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTasksKill,
+}
+
+// taskInfo is one background task in tasks output
+type taskInfo struct {
+	Worktree   string     `json:"worktree"`
+	Task       string     `json:"task"`
+	Command    string     `json:"command"`
+	State      string     `json:"state"`
+	ExitCode   *int       `json:"exit_code"` // null until the command exits
+	Error      string     `json:"error,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+func newTaskInfo(wtPath string, task runner.Task) taskInfo {
+	info := taskInfo{
+		Worktree:   wtPath,
+		Task:       workspace.PostInstallJob,
+		Command:    task.Command.String(),
+		State:      task.State,
+		Error:      task.Error,
+		StartedAt:  optionalTime(task.StartedAt),
+		FinishedAt: optionalTime(task.FinishedAt),
+	}
+	if exitCode(task) != "-" {
+		code := task.ExitCode
+		info.ExitCode = &code
+	}
+	return info
+}
+
+func init() {
+	addOutputFlags(tasksCmd)
+	tasksLogsCmd.Flags().BoolVarP(&tasksFollow, "follow", "f", false, "Keep printing output until the tasks finish")
+	tasksCmd.AddCommand(tasksLogsCmd)
+	tasksCmd.AddCommand(tasksKillCmd)
+	rootCmd.AddCommand(tasksCmd)
+}
+
+func runTasks(cmd *cobra.Command, args []string) error {
+	ws, err := workspace.Open(ui.NewSilent(false))
+	if err != nil {
+		return err
+	}
+
+	var worktrees []git.Worktree
+	if len(args) == 1 {
+		wt, err := ws.FindWorktree(args[0])
+		if err != nil {
+			return err
+		}
+		worktrees = []git.Worktree{*wt}
+	} else {
+		worktrees, err = ws.ListWorktrees()
+		if err != nil {
+			return err
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "WORKTREE\tTASK\tSTATE\tEXIT\tDURATION")
+	fmt.Fprintln(w, "--------\t----\t-----\t----\t--------")
+
+	found := false
+	var infos []taskInfo
+	for _, wt := range worktrees {
+		// The state lives in the git dir, but it cannot be resolved without
+		// the worktree directory
+		if wt.Prunable {
+			found = true
+			infos = append(infos, taskInfo{
+				Worktree: wt.Path,
+				Task:     workspace.PostInstallJob,
+				State:    "missing",
+				Error:    missingWorktree,
+			})
+			fmt.Fprintf(w, "%s\t-\t%s\t-\t-\n", wt.Path, missingWorktree)
+			continue
+		}
+
+		job, err := loadJob(wt.Path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		found = true
+		for _, task := range job.Tasks {
+			infos = append(infos, newTaskInfo(wt.Path, task))
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+				wt.Path, task.Command, task.State, exitCode(task), taskDuration(task))
+		}
+	}
+
+	if structuredOutput() {
+		return printItems("tasks", infos)
+	}
+	if !found {
+		fmt.Println("No background tasks found.")
+		return nil
+	}
+
+	w.Flush()
+	return nil
+}
+
+// missingWorktree reports a worktree whose directory was deleted
+const missingWorktree = "missing; run 'git worktree prune'"
+
+func runTasksLogs(cmd *cobra.Command, args []string) error {
+	stateDir, err := jobStateDir(args[0])
+	if err != nil {
+		return err
+	}
+
+	err = runner.CopyLog(stateDir, workspace.PostInstallJob, os.Stdout, tasksFollow)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no background tasks found for '%s'", args[0])
+	}
+	return err
+}
+
+func runTasksKill(cmd *cobra.Command, args []string) error {
+	stateDir, err := jobStateDir(args[0])
+	if err != nil {
+		return err
+	}
+
+	job, err := runner.KillJob(stateDir, workspace.PostInstallJob)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no background tasks found for '%s'", args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Killed background tasks in %s (pid %d).\n", job.Dir, job.PID)
+	return nil
+}
+
+func jobStateDir(target string) (string, error) {
+	ws, err := workspace.Open(ui.NewSilent(false))
+	if err != nil {
+		return "", err
+	}
+
+	wt, err := ws.FindWorktree(target)
+	if err != nil {
+		return "", err
+	}
+	return workspace.StateDir(wt.Path)
+}
+
+func loadJob(wtPath string) (*runner.Job, error) {
+	stateDir, err := workspace.StateDir(wtPath)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := runner.LoadJob(stateDir, workspace.PostInstallJob)
+	if err != nil {
+		return nil, err
+	}
+	job.Reconcile()
+	return job, nil
+}
+
+func exitCode(task runner.Task) string {
+	switch task.State {
+	case runner.StateSucceeded, runner.StateFailed:
+		return fmt.Sprintf("%d", task.ExitCode)
+	default:
+		return "-"
+	}
+}
+
+func taskDuration(task runner.Task) string {
+	if task.StartedAt.IsZero() {
+		return "-"
+	}
+
+	end := task.FinishedAt
+	if end.IsZero() {
+		end = time.Now()
+	}
+	return formatDuration(end.Sub(task.StartedAt))
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
@@ -10,11 +10,27 @@ import (
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print version information",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if structuredOutput() {
+			return printItem("build", buildInfo{
+				Version:   version.Version,
+				GitCommit: version.GitCommit,
+				BuildDate: version.BuildDate,
+			})
+		}
 		fmt.Println("wm", version.String())
+		return nil
 	},
 }
 
+// buildInfo is version output
+type buildInfo struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	BuildDate string `json:"build_date"`
+}
+
 func init() {
+	addOutputFlags(versionCmd)
 	rootCmd.AddCommand(versionCmd)
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 
 	"gopkg.in/yaml.v3"
 )
@@ -15,7 +16,10 @@ type rawConfig struct {
 	Version  int             `yaml:"version"`
 	Worktree WorktreeConfig  `yaml:"worktree"`
 	Scan     ScanConfig      `yaml:"scan"`
+	Ports    yaml.Node       `yaml:"ports"`
+	Symlinks SymlinkConfig   `yaml:"symlinks"`
 	Sync     []yaml.Node     `yaml:"sync"`
+	Seed     SeedConfig      `yaml:"seed"`
 	Tasks    TasksConfig     `yaml:"tasks"`
 }
 
@@ -26,19 +30,38 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config: %w", err)
 	}
 
-	// Parse into raw config first to handle mixed sync types
-	var raw rawConfig
+	// Build the final config
+	cfg := NewConfig()
+
+	// Parse into raw config first to handle mixed sync types. Sections
+	// missing from the file keep their defaults.
+	raw := rawConfig{Worktree: cfg.Worktree, Scan: cfg.Scan}
 	if err := yaml.Unmarshal(data, &raw); err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
-	// Build the final config
-	cfg := NewConfig()
 	cfg.Version = raw.Version
 	cfg.Worktree = raw.Worktree
 	cfg.Scan = raw.Scan
+	cfg.Symlinks = raw.Symlinks
+	cfg.Seed = raw.Seed
 	cfg.Tasks = raw.Tasks
 
+	if err := validateWorktree(cfg.Worktree); err != nil {
+		return nil, err
+	}
+
+	// Writing a ports section opts in to reserving ports
+	if !raw.Ports.IsZero() {
+		cfg.Ports.Enabled = true
+		if err := raw.Ports.Decode(&cfg.Ports); err != nil {
+			return nil, fmt.Errorf("failed to parse ports: %w", err)
+		}
+	}
+	if err := validatePorts(cfg.Ports); err != nil {
+		return nil, err
+	}
+
 	// Handle mixed string/object sync items
 	cfg.Sync = make([]SyncItem, len(raw.Sync))
 	for i, node := range raw.Sync {
@@ -46,6 +69,7 @@ func LoadConfig(path string) (*Config, error) {
 			// String value - just a path
 			cfg.Sync[i] = SyncItem{
 				Src:  node.Value,
+				Dst:  node.Value,
 				Mode: "copy",
 				When: "always",
 			}
@@ -55,6 +79,25 @@ func LoadConfig(path string) (*Config, error) {
 			if err := node.Decode(&item); err != nil {
 				return nil, fmt.Errorf("failed to parse sync item %d: %w", i, err)
 			}
+			if item.UntrackedIgnored && item.Src != "" {
+				return nil, fmt.Errorf("sync item %d: src cannot be combined with untracked_ignored", i)
+			}
+			switch item.Mode {
+			case "", "copy", "symlink", "reflink", "hardlink", "template":
+			default:
+				return nil, fmt.Errorf("sync item %d: unknown mode %q", i, item.Mode)
+			}
+			if item.Fallback != "" && item.Fallback != "copy" && item.Fallback != "error" {
+				return nil, fmt.Errorf("sync item %d: unknown fallback %q", i, item.Fallback)
+			}
+			if item.Relative != nil && item.Mode != "symlink" {
+				return nil, fmt.Errorf("sync item %d: relative only applies to mode: symlink", i)
+			}
+			switch item.OnConflict {
+			case "", "prompt", "overwrite", "skip", "backup":
+			default:
+				return nil, fmt.Errorf("sync item %d: unknown on_conflict policy %q", i, item.OnConflict)
+			}
 			// Set defaults
 			if item.Mode == "" {
 				item.Mode = "copy"
@@ -72,6 +115,41 @@ func LoadConfig(path string) (*Config, error) {
 	return cfg, nil
 }
 
+// pathPlaceholder matches the {name} placeholders of worktree.path_template
+var pathPlaceholder = regexp.MustCompile(`\{[^}]*\}`)
+
+func validateWorktree(wt WorktreeConfig) error {
+	for _, placeholder := range pathPlaceholder.FindAllString(wt.PathTemplate, -1) {
+		switch placeholder {
+		case "{repo}", "{branch}", "{branch_slug}":
+		default:
+			return fmt.Errorf("worktree.path_template: unknown placeholder %s", placeholder)
+		}
+	}
+	switch wt.OnCollision {
+	case "", "error", "suffix":
+	default:
+		return fmt.Errorf("worktree.on_collision: unknown policy %q", wt.OnCollision)
+	}
+	if wt.Slug.MaxLength < 0 {
+		return fmt.Errorf("worktree.slug.max_length must not be negative")
+	}
+	return nil
+}
+
+func validatePorts(p PortsConfig) error {
+	if !p.Enabled {
+		return nil
+	}
+	if p.BlockSize <= 0 {
+		return fmt.Errorf("ports.block_size must be positive")
+	}
+	if p.Start <= 0 || p.End > 65535 || p.End < p.Start+p.BlockSize-1 {
+		return fmt.Errorf("ports: a block of %d does not fit in %d-%d", p.BlockSize, p.Start, p.End)
+	}
+	return nil
+}
+
 // SaveConfig writes a Config to a .wm.yaml file
 func SaveConfig(path string, cfg *Config) error {
 	data, err := yaml.Marshal(cfg)
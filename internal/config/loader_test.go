@@ -1,8 +1,10 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -117,3 +119,366 @@ func TestSaveConfig(t *testing.T) {
 		t.Errorf("expected base_dir '../test_wm', got %s", loaded.Worktree.BaseDir)
 	}
 }
+
+func TestLoadConfigCommandForms(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".wm.yaml")
+
+	content := []byte(`version: 1
+tasks:
+  shell: "bash -lc"
+  post_install:
+    mode: foreground
+    shell: zsh
+    commands:
+      - "source .venv/bin/activate && pip install -e ."
+      - ["go", "mod", "download"]
+      - run: "make setup"
+        shell: "sh -ec"
+`)
+	if err := os.WriteFile(configPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	if cfg.Tasks.Shell != "bash -lc" {
+		t.Errorf("expected tasks shell 'bash -lc', got %s", cfg.Tasks.Shell)
+	}
+	if cfg.Tasks.PostInstall.Shell != "zsh" {
+		t.Errorf("expected post_install shell 'zsh', got %s", cfg.Tasks.PostInstall.Shell)
+	}
+
+	cmds := cfg.Tasks.PostInstall.Commands
+	if len(cmds) != 3 {
+		t.Fatalf("expected 3 commands, got %d", len(cmds))
+	}
+	if cmds[0].Run != "source .venv/bin/activate && pip install -e ." {
+		t.Errorf("unexpected string command: %+v", cmds[0])
+	}
+	if len(cmds[1].Args) != 3 || cmds[1].Args[0] != "go" {
+		t.Errorf("unexpected argv command: %+v", cmds[1])
+	}
+	if cmds[2].Run != "make setup" || cmds[2].Shell != "sh -ec" {
+		t.Errorf("unexpected object command: %+v", cmds[2])
+	}
+}
+
+func TestLoadConfigCommandRunAndArgs(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".wm.yaml")
+
+	content := []byte(`tasks:
+  post_install:
+    commands:
+      - run: "make"
+        args: ["make"]
+`)
+	if err := os.WriteFile(configPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadConfig(configPath); err == nil {
+		t.Error("expected error for command with both run and args")
+	}
+}
+
+func TestSaveConfigCommands(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".wm.yaml")
+
+	cfg := NewConfig()
+	cfg.Tasks.PostInstall.Commands = []Command{
+		{Run: "pnpm install"},
+		{Args: []string{"go", "mod", "download"}},
+		{Run: "make", Shell: "bash -lc"},
+	}
+
+	if err := SaveConfig(configPath, cfg); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+
+	loaded, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	cmds := loaded.Tasks.PostInstall.Commands
+	if len(cmds) != 3 {
+		t.Fatalf("expected 3 commands, got %d", len(cmds))
+	}
+	if cmds[0].Run != "pnpm install" || len(cmds[1].Args) != 3 || cmds[2].Shell != "bash -lc" {
+		t.Errorf("commands did not round-trip: %+v", cmds)
+	}
+}
+
+func TestLoadConfigNotify(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".wm.yaml")
+
+	content := []byte(`tasks:
+  post_install:
+    notify: "Ready to code!"
+`)
+	if err := os.WriteFile(configPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	notify := cfg.Tasks.PostInstall.Notify
+	if notify.Message != "Ready to code!" || !notify.Bell {
+		t.Errorf("expected message with bell, got %+v", notify)
+	}
+
+	content = []byte(`tasks:
+  post_install:
+    notify:
+      command: 'notify-send wm "{{.Task}} {{.Status}}"'
+      webhook: https://example.com/hook
+`)
+	if err := os.WriteFile(configPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err = LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	notify = cfg.Tasks.PostInstall.Notify
+	if notify.Bell || notify.Webhook != "https://example.com/hook" || !strings.Contains(notify.Command, "notify-send") {
+		t.Errorf("unexpected notify config: %+v", notify)
+	}
+}
+
+func TestLoadConfigHooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".wm.yaml")
+
+	content := []byte(`tasks:
+  pre_add:
+    - "./scripts/check.sh"
+  pre_remove:
+    shell: bash
+    commands:
+      - "docker compose down"
+      - ["pg_dump", "-f", "dump.sql"]
+`)
+	if err := os.WriteFile(configPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	if len(cfg.Tasks.PreAdd.Commands) != 1 || cfg.Tasks.PreAdd.Commands[0].Run != "./scripts/check.sh" {
+		t.Errorf("unexpected pre_add hook: %+v", cfg.Tasks.PreAdd)
+	}
+	if cfg.Tasks.PreRemove.Shell != "bash" || len(cfg.Tasks.PreRemove.Commands) != 2 {
+		t.Errorf("unexpected pre_remove hook: %+v", cfg.Tasks.PreRemove)
+	}
+	if len(cfg.Tasks.PostAdd.Commands) != 0 {
+		t.Errorf("expected no post_add hook, got %+v", cfg.Tasks.PostAdd)
+	}
+}
+
+func TestLoadConfigKeepsDefaults(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".wm.yaml")
+
+	if err := os.WriteFile(configPath, []byte("sync:\n  - .env\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	defaults := NewConfig()
+	if cfg.Worktree.BaseDir != defaults.Worktree.BaseDir {
+		t.Errorf("expected default base_dir, got %q", cfg.Worktree.BaseDir)
+	}
+	if len(cfg.Scan.IgnoreDirs) != len(defaults.Scan.IgnoreDirs) {
+		t.Errorf("expected default ignore_dirs, got %v", cfg.Scan.IgnoreDirs)
+	}
+	if cfg.Sync[0].Dst != ".env" {
+		t.Errorf("expected dst to default to src, got %q", cfg.Sync[0].Dst)
+	}
+}
+
+func TestLoadConfigInvalidSyncMode(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".wm.yaml")
+
+	content := "sync:\n  - src: weights.bin\n    mode: reflnk\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadConfig(configPath); err == nil {
+		t.Error("expected error for unknown sync mode")
+	}
+}
+
+func TestLoadConfigSeed(t *testing.T) {
+	tests := []struct {
+		yaml string
+		want SeedConfig
+	}{
+		{"seed: true\n", SeedConfig{Enabled: true}},
+		{"seed: false\n", SeedConfig{}},
+		{"seed: [node_modules, target]\n", SeedConfig{Enabled: true, Dirs: []string{"node_modules", "target"}}},
+		{"seed:\n  dirs: [node_modules]\n  lockfiles: [pnpm-lock.yaml]\n  mode: copy\n",
+			SeedConfig{Enabled: true, Dirs: []string{"node_modules"}, Lockfiles: []string{"pnpm-lock.yaml"}, Mode: "copy"}},
+	}
+
+	for _, tt := range tests {
+		configPath := filepath.Join(t.TempDir(), ".wm.yaml")
+		if err := os.WriteFile(configPath, []byte(tt.yaml), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := LoadConfig(configPath)
+		if err != nil {
+			t.Fatalf("LoadConfig(%q) failed: %v", tt.yaml, err)
+		}
+		if fmt.Sprint(cfg.Seed) != fmt.Sprint(tt.want) {
+			t.Errorf("LoadConfig(%q) seed = %+v, want %+v", tt.yaml, cfg.Seed, tt.want)
+		}
+	}
+}
+
+func TestLoadConfigRelativeSymlinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".wm.yaml")
+
+	content := `symlinks:
+  relative: true
+sync:
+  - src: .env
+    mode: symlink
+  - src: data
+    mode: symlink
+    relative: false
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if !cfg.Symlinks.Relative {
+		t.Error("expected symlinks.relative to be set")
+	}
+	if cfg.Sync[0].Relative != nil {
+		t.Errorf("expected .env to use the default, got %v", *cfg.Sync[0].Relative)
+	}
+	if cfg.Sync[1].Relative == nil || *cfg.Sync[1].Relative {
+		t.Error("expected data to override relative with false")
+	}
+
+	content = "sync:\n  - src: .env\n    relative: true\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(configPath); err == nil {
+		t.Error("expected error for relative on a copied item")
+	}
+}
+
+func TestLoadConfigWorktreePaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".wm.yaml")
+
+	content := `worktree:
+  path_template: "{repo}-{branch_slug}"
+  branch_prefix: alice/
+  slug:
+    lowercase: true
+    max_length: 40
+  on_collision: suffix
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	wt := cfg.Worktree
+	if wt.PathTemplate != "{repo}-{branch_slug}" || wt.BranchPrefix != "alice/" || wt.OnCollision != "suffix" {
+		t.Errorf("unexpected worktree config: %+v", wt)
+	}
+	if wt.Slug != (SlugConfig{Separator: "-", Lowercase: true, MaxLength: 40}) {
+		t.Errorf("expected slug settings merged with the default separator, got %+v", wt.Slug)
+	}
+	if wt.BaseDir != NewConfig().Worktree.BaseDir {
+		t.Errorf("expected default base_dir, got %q", wt.BaseDir)
+	}
+
+	for _, invalid := range []string{
+		"worktree:\n  path_template: \"{branch_name}\"\n",
+		"worktree:\n  on_collision: overwrite\n",
+	} {
+		if err := os.WriteFile(configPath, []byte(invalid), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadConfig(configPath); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestLoadConfigPorts(t *testing.T) {
+	tests := []struct {
+		yaml    string
+		enabled bool
+		start   int
+		size    int
+	}{
+		{"sync:\n  - .env\n", false, 3100, 10},
+		{"ports:\n  block_size: 5\n", true, 3100, 5},
+		{"ports:\n  enabled: false\n  block_size: 0\n", false, 3100, 0},
+	}
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".wm.yaml")
+	for _, tt := range tests {
+		if err := os.WriteFile(configPath, []byte(tt.yaml), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := LoadConfig(configPath)
+		if err != nil {
+			t.Fatalf("LoadConfig(%q) failed: %v", tt.yaml, err)
+		}
+		if p := cfg.Ports; p.Enabled != tt.enabled || p.Start != tt.start || p.BlockSize != tt.size {
+			t.Errorf("LoadConfig(%q): unexpected ports %+v", tt.yaml, p)
+		}
+	}
+
+	for _, invalid := range []string{
+		"ports:\n  block_size: 0\n",
+		"ports:\n  start: 4000\n  end: 3000\n",
+		"ports:\n  start: 65530\n  end: 65600\n",
+	} {
+		if err := os.WriteFile(configPath, []byte(invalid), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadConfig(configPath); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
@@ -1,38 +1,258 @@
 package config
 
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
 // Config represents the .wm.yaml file structure
 type Config struct {
 	Version  int            `yaml:"version"`
 	Worktree WorktreeConfig `yaml:"worktree"`
 	Scan     ScanConfig     `yaml:"scan"`
+	Ports    PortsConfig    `yaml:"ports"`
+	Symlinks SymlinkConfig  `yaml:"symlinks,omitempty"`
 	Sync     []SyncItem     `yaml:"sync"`
+	Seed     SeedConfig     `yaml:"seed,omitempty"`
 	Tasks    TasksConfig    `yaml:"tasks"`
 }
 
 type WorktreeConfig struct {
-	BaseDir string `yaml:"base_dir"`
+	BaseDir      string     `yaml:"base_dir"`
+	PathTemplate string     `yaml:"path_template,omitempty"` // Directory under base_dir; {repo}, {branch} and {branch_slug} are replaced
+	BranchPrefix string     `yaml:"branch_prefix,omitempty"` // Prepended to new branch names, e.g. "alice/"
+	Slug         SlugConfig `yaml:"slug,omitempty"`          // How {branch_slug} is derived from the branch
+	OnCollision  string     `yaml:"on_collision,omitempty"`  // When the directory exists: "error" (default) or "suffix"
+	Remotes      []string   `yaml:"remotes,omitempty"`       // Remotes searched, in order, for branches that do not exist locally
+	DefaultBase  string     `yaml:"default_base,omitempty"`  // Start point of new branches, e.g. origin/main; default: the main worktree's HEAD
+}
+
+// SlugConfig controls how branch names become directory names. Characters
+// other than letters, digits, '.', '_' and '-' are replaced by Separator.
+type SlugConfig struct {
+	Separator string `yaml:"separator,omitempty"`  // Default "-"
+	Lowercase bool   `yaml:"lowercase,omitempty"`  // Lowercase the result
+	MaxLength int    `yaml:"max_length,omitempty"` // Truncate to this many characters; 0 for no limit
+}
+
+// PortsConfig controls the blocks of ports reserved for each worktree.
+// Ports are only reserved when .wm.yaml has a ports section that does not
+// set enabled: false.
+type PortsConfig struct {
+	Enabled   bool `yaml:"enabled"`
+	Start     int  `yaml:"start"`      // First port handed out
+	End       int  `yaml:"end"`        // Last port handed out
+	BlockSize int  `yaml:"block_size"` // Ports reserved per worktree
+}
+
+// SymlinkConfig holds defaults for mode: symlink sync items
+type SymlinkConfig struct {
+	Relative bool `yaml:"relative,omitempty"` // Links survive moving the repo and worktrees together
 }
 
 type ScanConfig struct {
 	IgnoreDirs []string `yaml:"ignore_dirs"`
 }
 
-// SyncItem can be a string path or an object with src/dst/mode/when
+// SyncItem can be a string path or an object with src/dst/mode/when.
+// Src may name a file or a directory; directories are copied recursively.
+// With UntrackedIgnored the sources are instead every file git ignores in
+// the repo root, narrowed down by Include and Exclude.
 type SyncItem struct {
-	Src  string `yaml:"src"`
-	Dst  string `yaml:"dst,omitempty"`
-	Mode string `yaml:"mode,omitempty"` // "copy" (default) or "symlink"
-	When string `yaml:"when,omitempty"` // "always" (default) or "missing"
+	Src              string   `yaml:"src,omitempty"`
+	Dst              string   `yaml:"dst,omitempty"`
+	Mode             string   `yaml:"mode,omitempty"`              // "copy" (default), "symlink", "reflink", "hardlink" or "template"
+	Fallback         string   `yaml:"fallback,omitempty"`          // When reflink/hardlink fails: "copy" (default) or "error"
+	When             string   `yaml:"when,omitempty"`              // "always" (default) or "missing"
+	OnConflict       string   `yaml:"on_conflict,omitempty"`       // Local edits: "prompt" (default), "overwrite", "skip" or "backup"
+	Relative         *bool    `yaml:"relative,omitempty"`          // mode: symlink with a relative target; defaults to symlinks.relative
+	Exclude          []string `yaml:"exclude,omitempty"`           // Patterns skipped inside a directory
+	UntrackedIgnored bool     `yaml:"untracked_ignored,omitempty"` // Sync files ignored by git instead of Src
+	Include          []string `yaml:"include,omitempty"`           // Limits untracked_ignored to matching paths
+}
+
+// SeedConfig clones installed dependency directories from the main worktree
+// into new worktrees. It can be true, a list of directories or an object;
+// directories and lockfiles default to those of the detected ecosystem.
+type SeedConfig struct {
+	Enabled   bool     `yaml:"-"`
+	Dirs      []string `yaml:"dirs,omitempty"`      // e.g. node_modules, target; may contain "**"
+	Lockfiles []string `yaml:"lockfiles,omitempty"` // Seeding is skipped unless these match the main worktree
+	Mode      string   `yaml:"mode,omitempty"`      // "reflink" (default), "copy" or "hardlink"
+}
+
+// UnmarshalYAML accepts a boolean, a list of directories or the object form
+func (c *SeedConfig) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		var enabled bool
+		if err := node.Decode(&enabled); err != nil {
+			return err
+		}
+		*c = SeedConfig{Enabled: enabled}
+		return nil
+	case yaml.SequenceNode:
+		var dirs []string
+		if err := node.Decode(&dirs); err != nil {
+			return err
+		}
+		*c = SeedConfig{Enabled: true, Dirs: dirs}
+		return nil
+	}
+
+	type plain SeedConfig
+	var seed plain
+	if err := node.Decode(&seed); err != nil {
+		return err
+	}
+	switch seed.Mode {
+	case "", "reflink", "copy", "hardlink":
+	default:
+		return fmt.Errorf("line %d: unknown seed mode %q", node.Line, seed.Mode)
+	}
+	*c = SeedConfig(seed)
+	c.Enabled = true
+	return nil
 }
 
 type TasksConfig struct {
+	Shell       string            `yaml:"shell,omitempty"` // Default shell for string commands, e.g. "bash -lc"
+	PreAdd      HookConfig        `yaml:"pre_add,omitempty"`
+	PostAdd     HookConfig        `yaml:"post_add,omitempty"`
+	PreRemove   HookConfig        `yaml:"pre_remove,omitempty"`
+	PostRemove  HookConfig        `yaml:"post_remove,omitempty"`
 	PostInstall PostInstallConfig `yaml:"post_install"`
 }
 
+// HookConfig is a lifecycle hook. It can be a list of commands or an object
+// with shell/commands.
+type HookConfig struct {
+	Shell    string    `yaml:"shell,omitempty"` // Overrides tasks.shell for this hook
+	Commands []Command `yaml:"commands"`
+}
+
+// UnmarshalYAML accepts a bare command list or the object form
+func (h *HookConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		var cmds []Command
+		if err := node.Decode(&cmds); err != nil {
+			return err
+		}
+		*h = HookConfig{Commands: cmds}
+		return nil
+	}
+
+	type plain HookConfig
+	var hook plain
+	if err := node.Decode(&hook); err != nil {
+		return err
+	}
+	*h = HookConfig(hook)
+	return nil
+}
+
 type PostInstallConfig struct {
-	Mode     string   `yaml:"mode"`
-	Commands []string `yaml:"commands"`
-	Notify   string   `yaml:"notify,omitempty"`
+	Mode     string       `yaml:"mode"`
+	Shell    string       `yaml:"shell,omitempty"` // Overrides tasks.shell for this task
+	Commands []Command    `yaml:"commands"`
+	Notify   NotifyConfig `yaml:"notify,omitempty"`
+}
+
+// NotifyConfig controls how wm reports that background tasks finished. It can
+// be a string, used as the message with a terminal bell, or an object.
+// Message and Command are Go templates with .Worktree, .Task, .Command,
+// .Status, .ExitCode and .Duration available; Command also gets them as
+// WM_* environment variables, which are safe to quote in the script.
+type NotifyConfig struct {
+	Message string `yaml:"message,omitempty" json:"message,omitempty"` // Text shown on the terminal and sent to webhooks
+	Bell    bool   `yaml:"bell,omitempty" json:"bell,omitempty"`       // Ring the bell on the terminal that ran wm add
+	Command string `yaml:"command,omitempty" json:"command,omitempty"` // e.g. notify-send "wm" "$WM_TASK $WM_STATUS"
+	Webhook string `yaml:"webhook,omitempty" json:"webhook,omitempty"` // URL that receives a JSON POST
+	Socket  string `yaml:"socket,omitempty" json:"socket,omitempty"`   // Unix socket that receives a JSON line
+}
+
+// IsZero reports whether no notification is configured
+func (n NotifyConfig) IsZero() bool {
+	return n == NotifyConfig{}
+}
+
+// UnmarshalYAML accepts a plain message or the object form
+func (n *NotifyConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*n = NotifyConfig{Message: node.Value, Bell: true}
+		return nil
+	}
+
+	type plain NotifyConfig
+	var cfg plain
+	if err := node.Decode(&cfg); err != nil {
+		return err
+	}
+	*n = NotifyConfig(cfg)
+	return nil
+}
+
+// Command can be a string run through a shell, a list of arguments executed
+// directly, or an object with run/args/shell
+type Command struct {
+	Run   string   `yaml:"run,omitempty" json:"run,omitempty"`     // Script passed to the shell
+	Args  []string `yaml:"args,omitempty" json:"args,omitempty"`   // argv executed without a shell
+	Shell string   `yaml:"shell,omitempty" json:"shell,omitempty"` // Overrides the task shell for this command
+}
+
+// String returns a human readable form of the command
+func (c Command) String() string {
+	if len(c.Args) > 0 {
+		return strings.Join(c.Args, " ")
+	}
+	return c.Run
+}
+
+// IsEmpty reports whether the command has nothing to run
+func (c Command) IsEmpty() bool {
+	return c.Run == "" && len(c.Args) == 0
+}
+
+// UnmarshalYAML accepts the string, list and object forms of a command
+func (c *Command) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*c = Command{Run: node.Value}
+		return nil
+	case yaml.SequenceNode:
+		var args []string
+		if err := node.Decode(&args); err != nil {
+			return err
+		}
+		*c = Command{Args: args}
+		return nil
+	}
+
+	type plain Command
+	var cmd plain
+	if err := node.Decode(&cmd); err != nil {
+		return err
+	}
+	if cmd.Run != "" && len(cmd.Args) > 0 {
+		return fmt.Errorf("line %d: command cannot have both run and args", node.Line)
+	}
+	*c = Command(cmd)
+	return nil
+}
+
+// MarshalYAML writes commands back in their shortest form
+func (c Command) MarshalYAML() (interface{}, error) {
+	switch {
+	case c.Shell != "":
+		type plain Command
+		return plain(c), nil
+	case len(c.Args) > 0:
+		return c.Args, nil
+	default:
+		return c.Run, nil
+	}
 }
 
 // NewConfig returns a Config with default values
@@ -40,11 +260,20 @@ func NewConfig() *Config {
 	return &Config{
 		Version: 1,
 		Worktree: WorktreeConfig{
-			BaseDir: "../wm_{repo}",
+			BaseDir:      "../wm_{repo}",
+			PathTemplate: "{branch}",
+			Slug:         SlugConfig{Separator: "-"},
+			OnCollision:  "error",
+			Remotes:      []string{"origin"},
 		},
 		Scan: ScanConfig{
 			IgnoreDirs: []string{".git", "node_modules", "dist", "build", ".next", "target", "vendor"},
 		},
+		Ports: PortsConfig{
+			Start:     3100,
+			End:       3999,
+			BlockSize: 10,
+		},
 		Sync:  []SyncItem{},
 		Tasks: TasksConfig{},
 	}
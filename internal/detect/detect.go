@@ -12,6 +12,8 @@ type DetectionResult struct {
 	PackageManager string
 	InstallCommand string
 	IsMonorepo     bool
+	Lockfiles      []string // Files pinning the dependencies, relative to dir
+	DependencyDirs []string // Installed dependency directories, may contain "**"
 }
 
 // Detect analyzes a directory and returns package manager info
@@ -22,6 +24,8 @@ func Detect(dir string) DetectionResult {
 			PackageManager: "pnpm",
 			InstallCommand: "pnpm install",
 			IsMonorepo:     true,
+			Lockfiles:      []string{"pnpm-lock.yaml"},
+			DependencyDirs: []string{"**/node_modules"},
 		}
 	}
 
@@ -31,6 +35,8 @@ func Detect(dir string) DetectionResult {
 			PackageManager: "pnpm",
 			InstallCommand: "pnpm install",
 			IsMonorepo:     false,
+			Lockfiles:      []string{"pnpm-lock.yaml"},
+			DependencyDirs: []string{"node_modules"},
 		}
 	}
 
@@ -41,6 +47,8 @@ func Detect(dir string) DetectionResult {
 			PackageManager: "yarn",
 			InstallCommand: "yarn install",
 			IsMonorepo:     isMonorepo,
+			Lockfiles:      []string{"yarn.lock"},
+			DependencyDirs: nodeModulesDirs(isMonorepo),
 		}
 	}
 
@@ -55,6 +63,8 @@ func Detect(dir string) DetectionResult {
 			PackageManager: pm,
 			InstallCommand: "npm install",
 			IsMonorepo:     isMonorepo,
+			Lockfiles:      []string{"package-lock.json"},
+			DependencyDirs: nodeModulesDirs(isMonorepo),
 		}
 	}
 
@@ -65,6 +75,8 @@ func Detect(dir string) DetectionResult {
 			PackageManager: "cargo",
 			InstallCommand: "cargo build",
 			IsMonorepo:     isMonorepo,
+			Lockfiles:      []string{"Cargo.lock"},
+			DependencyDirs: []string{"target"},
 		}
 	}
 
@@ -74,6 +86,7 @@ func Detect(dir string) DetectionResult {
 			PackageManager: "go",
 			InstallCommand: "go mod download",
 			IsMonorepo:     true,
+			Lockfiles:      []string{"go.work.sum"},
 		}
 	}
 
@@ -83,6 +96,7 @@ func Detect(dir string) DetectionResult {
 			PackageManager: "go",
 			InstallCommand: "go mod download",
 			IsMonorepo:     false,
+			Lockfiles:      []string{"go.sum"},
 		}
 	}
 
@@ -92,6 +106,7 @@ func Detect(dir string) DetectionResult {
 			PackageManager: "poetry",
 			InstallCommand: "poetry install",
 			IsMonorepo:     false,
+			Lockfiles:      []string{"poetry.lock"},
 		}
 	}
 
@@ -101,6 +116,7 @@ func Detect(dir string) DetectionResult {
 			PackageManager: "pipenv",
 			InstallCommand: "pipenv install",
 			IsMonorepo:     false,
+			Lockfiles:      []string{"Pipfile.lock"},
 		}
 	}
 
@@ -114,6 +130,7 @@ func Detect(dir string) DetectionResult {
 			PackageManager: "pip",
 			InstallCommand: installCmd,
 			IsMonorepo:     false,
+			Lockfiles:      []string{"pyproject.toml"},
 		}
 	}
 
@@ -127,12 +144,22 @@ func Detect(dir string) DetectionResult {
 			PackageManager: "pip",
 			InstallCommand: installCmd,
 			IsMonorepo:     false,
+			Lockfiles:      []string{"requirements.txt"},
 		}
 	}
 
 	return DetectionResult{}
 }
 
+// nodeModulesDirs returns where npm-style package managers install to;
+// workspaces get a node_modules in every package as well
+func nodeModulesDirs(isMonorepo bool) []string {
+	if isMonorepo {
+		return []string{"**/node_modules"}
+	}
+	return []string{"node_modules"}
+}
+
 func fileExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
@@ -23,6 +23,14 @@ func TestDetectPnpm(t *testing.T) {
 	if result.InstallCommand != "pnpm install" {
 		t.Errorf("expected 'pnpm install', got %s", result.InstallCommand)
 	}
+
+	if len(result.Lockfiles) != 1 || result.Lockfiles[0] != "pnpm-lock.yaml" {
+		t.Errorf("expected pnpm-lock.yaml lockfile, got %v", result.Lockfiles)
+	}
+
+	if len(result.DependencyDirs) != 1 || result.DependencyDirs[0] != "**/node_modules" {
+		t.Errorf("expected **/node_modules, got %v", result.DependencyDirs)
+	}
 }
 
 func TestDetectNpm(t *testing.T) {
@@ -79,6 +87,10 @@ members = ["crates/*"]`
 	if result.InstallCommand != "cargo build" {
 		t.Errorf("expected 'cargo build', got %s", result.InstallCommand)
 	}
+
+	if len(result.DependencyDirs) != 1 || result.DependencyDirs[0] != "target" {
+		t.Errorf("expected target, got %v", result.DependencyDirs)
+	}
 }
 
 func TestDetectGo(t *testing.T) {
@@ -0,0 +1,111 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Status summarises the working tree and upstream of a worktree
+type Status struct {
+	Staged     int    // Files with changes in the index
+	Unstaged   int    // Tracked files with changes not in the index
+	Untracked  int    // Files git does not track and does not ignore
+	Conflicted int    // Files with unresolved merge conflicts
+	Upstream   string // e.g. origin/feature; empty if the branch has none
+	Ahead      int    // Commits not pushed to the upstream
+	Behind     int    // Upstream commits not merged yet
+}
+
+// Dirty reports whether the worktree has any local changes
+func (s Status) Dirty() bool {
+	return s.Staged+s.Unstaged+s.Untracked+s.Conflicted > 0
+}
+
+// GetStatus returns the working tree and upstream status of a worktree
+func GetStatus(dir string) (*Status, error) {
+	cmd := exec.Command("git", "status", "--porcelain=v2", "--branch")
+	cmd.Dir = dir
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git status failed: %w", err)
+	}
+
+	return parseStatus(out), nil
+}
+
+func parseStatus(data []byte) *Status {
+	status := &Status{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "# branch.upstream "):
+			status.Upstream = strings.TrimPrefix(line, "# branch.upstream ")
+		case strings.HasPrefix(line, "# branch.ab "):
+			fmt.Sscanf(strings.TrimPrefix(line, "# branch.ab "), "+%d -%d", &status.Ahead, &status.Behind)
+		case strings.HasPrefix(line, "1 "), strings.HasPrefix(line, "2 "):
+			// "1 XY ..." where X is the index and Y the working tree state
+			if len(line) < 4 {
+				continue
+			}
+			if line[2] != '.' {
+				status.Staged++
+			}
+			if line[3] != '.' {
+				status.Unstaged++
+			}
+		case strings.HasPrefix(line, "u "):
+			status.Conflicted++
+		case strings.HasPrefix(line, "? "):
+			status.Untracked++
+		}
+	}
+
+	return status
+}
+
+// AheadBehind counts the commits reachable from a but not b, and from b
+// but not a
+func AheadBehind(dir, a, b string) (ahead, behind int, err error) {
+	cmd := exec.Command("git", "rev-list", "--left-right", "--count", a+"..."+b)
+	cmd.Dir = dir
+
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to compare %s with %s: %w", a, b, err)
+	}
+
+	fields := strings.Fields(string(out))
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output %q", out)
+	}
+	ahead, _ = strconv.Atoi(fields[0])
+	behind, _ = strconv.Atoi(fields[1])
+	return ahead, behind, nil
+}
+
+// LastCommit returns the subject and commit time of HEAD
+func LastCommit(dir string) (subject string, when time.Time, err error) {
+	cmd := exec.Command("git", "log", "-1", "--format=%ct %s")
+	cmd.Dir = dir
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("git log failed: %w", err)
+	}
+
+	stamp, subject, _ := strings.Cut(strings.TrimSpace(string(out)), " ")
+	seconds, err := strconv.ParseInt(stamp, 10, 64)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("unexpected git log output %q", out)
+	}
+	return subject, time.Unix(seconds, 0), nil
+}
@@ -0,0 +1,87 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseStatus(t *testing.T) {
+	data := []byte(`# branch.oid abc1234567890
+# branch.head feature
+# branch.upstream origin/feature
+# branch.ab +2 -1
+1 M. N... 100644 100644 100644 aaa bbb staged.go
+1 .M N... 100644 100644 100644 aaa bbb unstaged.go
+1 MM N... 100644 100644 100644 aaa bbb both.go
+2 R. N... 100644 100644 100644 aaa bbb R100 new.go	old.go
+u UU N... 100644 100644 100644 100644 aaa bbb ccc conflict.go
+? untracked.txt
+? other.txt
+`)
+
+	status := parseStatus(data)
+	want := Status{Staged: 3, Unstaged: 2, Untracked: 2, Conflicted: 1, Upstream: "origin/feature", Ahead: 2, Behind: 1}
+	if *status != want {
+		t.Errorf("expected %+v, got %+v", want, *status)
+	}
+	if !status.Dirty() {
+		t.Error("expected status to be dirty")
+	}
+
+	clean := parseStatus([]byte("# branch.oid abc\n# branch.head main\n"))
+	if clean.Dirty() || clean.Upstream != "" {
+		t.Errorf("expected a clean status without upstream, got %+v", *clean)
+	}
+}
+
+func TestGetStatus(t *testing.T) {
+	repoDir := setupTestRepo(t)
+	if err := os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "new.txt"), []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := GetStatus(repoDir)
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if status.Unstaged != 1 || status.Untracked != 1 || status.Staged != 0 {
+		t.Errorf("unexpected status %+v", *status)
+	}
+}
+
+func TestAheadBehindAndLastCommit(t *testing.T) {
+	repoDir := setupTestRepo(t)
+	cmds := [][]string{
+		{"git", "branch", "base"},
+		{"git", "commit", "--allow-empty", "-m", "first"},
+		{"git", "commit", "--allow-empty", "-m", "second change"},
+	}
+	for _, args := range cmds {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = repoDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("command %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	ahead, behind, err := AheadBehind(repoDir, "HEAD", "base")
+	if err != nil {
+		t.Fatalf("AheadBehind failed: %v", err)
+	}
+	if ahead != 2 || behind != 0 {
+		t.Errorf("expected 2 ahead and 0 behind, got %d and %d", ahead, behind)
+	}
+
+	subject, when, err := LastCommit(repoDir)
+	if err != nil {
+		t.Fatalf("LastCommit failed: %v", err)
+	}
+	if subject != "second change" || when.IsZero() {
+		t.Errorf("unexpected last commit %q at %v", subject, when)
+	}
+}
@@ -5,15 +5,21 @@ import (
 	"bytes"
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
 // Worktree represents a git worktree entry
 type Worktree struct {
-	Path   string
-	HEAD   string
-	Branch string
-	Bare   bool
+	Path           string
+	HEAD           string
+	Branch         string
+	Bare           bool
+	Detached       bool
+	Locked         bool
+	LockReason     string
+	Prunable       bool // The worktree directory is gone; git worktree prune removes it
+	PrunableReason string
 }
 
 // ListWorktrees returns all worktrees for a repository
@@ -56,6 +62,14 @@ func parseWorktreeList(data []byte) []Worktree {
 			current.Branch = strings.TrimPrefix(branch, "refs/heads/")
 		case line == "bare":
 			current.Bare = true
+		case line == "locked" || strings.HasPrefix(line, "locked "):
+			current.Locked = true
+			current.LockReason = strings.TrimPrefix(strings.TrimPrefix(line, "locked"), " ")
+		case line == "detached":
+			current.Detached = true
+		case line == "prunable" || strings.HasPrefix(line, "prunable "):
+			current.Prunable = true
+			current.PrunableReason = strings.TrimPrefix(strings.TrimPrefix(line, "prunable"), " ")
 		}
 	}
 
@@ -88,6 +102,28 @@ func AddWorktree(repoDir, path, branch string, createBranch bool) error {
 	return nil
 }
 
+// AddWorktreeFrom creates a worktree on a new branch starting at startPoint.
+// With track the branch is set up to track startPoint, which must then be
+// a remote branch such as origin/feature.
+func AddWorktreeFrom(repoDir, path, branch, startPoint string, track bool) error {
+	args := []string{"worktree", "add"}
+	if track {
+		args = append(args, "--track")
+	} else {
+		args = append(args, "--no-track")
+	}
+	args = append(args, "-b", branch, path, startPoint)
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoDir
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git worktree add failed: %w\n%s", err, out)
+	}
+
+	return nil
+}
+
 // RemoveWorktree removes a worktree
 func RemoveWorktree(repoDir, path string, force bool) error {
 	args := []string{"worktree", "remove"}
@@ -113,6 +149,39 @@ func BranchExists(repoDir, branch string) bool {
 	return cmd.Run() == nil
 }
 
+// RemoteBranchExists checks if a remote-tracking branch such as
+// origin/feature exists
+func RemoteBranchExists(repoDir, remote, branch string) bool {
+	cmd := exec.Command("git", "show-ref", "--verify", "--quiet", "refs/remotes/"+remote+"/"+branch)
+	cmd.Dir = repoDir
+	return cmd.Run() == nil
+}
+
+// Fetch updates the remote-tracking branches of a remote
+func Fetch(repoDir, remote string) error {
+	cmd := exec.Command("git", "fetch", "--prune", remote)
+	cmd.Dir = repoDir
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git fetch %s failed: %w\n%s", remote, err, out)
+	}
+
+	return nil
+}
+
+// ResolveRef returns the commit hash a ref points to
+func ResolveRef(dir, ref string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	cmd.Dir = dir
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("unknown ref %s", ref)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
 // DeleteBranch deletes a local branch
 func DeleteBranch(repoDir, branch string, force bool) error {
 	flag := "-d"
@@ -143,6 +212,36 @@ func GetRepoRoot(dir string) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// GetMainWorktree returns the root of the main worktree, even when dir is
+// inside a linked worktree. For bare repositories it falls back to the
+// top level of dir.
+func GetMainWorktree(dir string) (string, error) {
+	worktrees, err := ListWorktrees(dir)
+	if err != nil {
+		return "", err
+	}
+
+	// git always lists the main worktree first
+	if len(worktrees) == 0 || worktrees[0].Bare {
+		return GetRepoRoot(dir)
+	}
+	return worktrees[0].Path, nil
+}
+
+// GetGitDir returns the absolute git directory of a worktree. For linked
+// worktrees this is .git/worktrees/<name> inside the main repository.
+func GetGitDir(dir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--absolute-git-dir")
+	cmd.Dir = dir
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get git directory: %w", err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
 // GetCurrentBranch returns the current branch name
 func GetCurrentBranch(dir string) (string, error) {
 	cmd := exec.Command("git", "branch", "--show-current")
@@ -155,3 +254,49 @@ func GetCurrentBranch(dir string) (string, error) {
 
 	return strings.TrimSpace(string(out)), nil
 }
+
+// ListIgnoredFiles returns the files in dir that are ignored by git but
+// present on disk, relative to dir. Wholly ignored directories are listed
+// once with a trailing slash instead of file by file.
+func ListIgnoredFiles(dir string) ([]string, error) {
+	cmd := exec.Command("git", "ls-files", "--others", "--ignored", "--exclude-standard", "--directory", "-z")
+	cmd.Dir = dir
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ignored files: %w", err)
+	}
+
+	entries := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	var files []string
+	for i, entry := range entries {
+		if entry == "" {
+			continue
+		}
+		// Untracked directories that merely contain ignored files are listed
+		// too, right before their contents; only keep wholly ignored ones
+		if strings.HasSuffix(entry, "/") && i+1 < len(entries) && strings.HasPrefix(entries[i+1], entry) {
+			continue
+		}
+		files = append(files, entry)
+	}
+	return files, nil
+}
+
+// GetCommonDir returns the absolute git directory shared by all worktrees
+// of a repository, i.e. the main repository's .git
+func GetCommonDir(dir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--git-common-dir")
+	cmd.Dir = dir
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get git common directory: %w", err)
+	}
+
+	commonDir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(dir, commonDir)
+	}
+	return commonDir, nil
+}
@@ -316,3 +316,198 @@ detached
 		t.Errorf("expected empty branch for detached HEAD, got %s", worktrees[0].Branch)
 	}
 }
+
+func TestGetMainWorktree(t *testing.T) {
+	repoDir := setupTestRepo(t)
+	wtPath := filepath.Join(t.TempDir(), "linked")
+
+	if err := AddWorktree(repoDir, wtPath, "linked", true); err != nil {
+		t.Fatalf("AddWorktree failed: %v", err)
+	}
+
+	main, err := GetMainWorktree(wtPath)
+	if err != nil {
+		t.Fatalf("GetMainWorktree failed: %v", err)
+	}
+
+	resolved, _ := filepath.EvalSymlinks(main)
+	if resolved != repoDir {
+		t.Errorf("expected main worktree %s, got %s", repoDir, resolved)
+	}
+}
+
+func TestListIgnoredFiles(t *testing.T) {
+	repoDir := setupTestRepo(t)
+
+	files := map[string]string{
+		".gitignore":                ".env\n*.env\nnode_modules/\n",
+		".env":                      "SECRET=1",
+		"node_modules/pkg/index.js": "",
+		"untracked.txt":             "",
+		"config/dev.env":            "",
+	}
+	for name, content := range files {
+		path := filepath.Join(repoDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ignored, err := ListIgnoredFiles(repoDir)
+	if err != nil {
+		t.Fatalf("ListIgnoredFiles failed: %v", err)
+	}
+
+	if len(ignored) != 3 || ignored[0] != ".env" || ignored[1] != "config/dev.env" || ignored[2] != "node_modules/" {
+		t.Errorf("expected [.env config/dev.env node_modules/], got %v", ignored)
+	}
+}
+
+func TestGetCommonDir(t *testing.T) {
+	repoDir := setupTestRepo(t)
+	wtPath := filepath.Join(t.TempDir(), "feature")
+	if err := AddWorktree(repoDir, wtPath, "feature", true); err != nil {
+		t.Fatalf("AddWorktree failed: %v", err)
+	}
+
+	for _, dir := range []string{repoDir, wtPath} {
+		commonDir, err := GetCommonDir(dir)
+		if err != nil {
+			t.Fatalf("GetCommonDir failed: %v", err)
+		}
+		if resolved, _ := filepath.EvalSymlinks(commonDir); resolved != filepath.Join(repoDir, ".git") {
+			t.Errorf("expected %s, got %s", filepath.Join(repoDir, ".git"), commonDir)
+		}
+	}
+}
+
+// setupRemote gives repoDir an origin remote holding a branch that only
+// exists there, as if someone else pushed it
+func setupRemote(t *testing.T, repoDir, branch string) {
+	t.Helper()
+	remoteDir := filepath.Join(t.TempDir(), "origin.git")
+
+	cmds := [][]string{
+		{"git", "init", "--bare", remoteDir},
+		{"git", "remote", "add", "origin", remoteDir},
+		{"git", "branch", branch},
+		{"git", "push", "origin", branch},
+		{"git", "branch", "-D", branch},
+		{"git", "update-ref", "-d", "refs/remotes/origin/" + branch},
+	}
+	for _, args := range cmds {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = repoDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("setup command %v failed: %v\n%s", args, err, out)
+		}
+	}
+}
+
+func TestRemoteBranchExists(t *testing.T) {
+	repoDir := setupTestRepo(t)
+	setupRemote(t, repoDir, "feature-x")
+
+	if RemoteBranchExists(repoDir, "origin", "feature-x") {
+		t.Error("expected origin/feature-x to be unknown before fetching")
+	}
+	if err := Fetch(repoDir, "origin"); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if !RemoteBranchExists(repoDir, "origin", "feature-x") {
+		t.Error("expected origin/feature-x to exist")
+	}
+	if RemoteBranchExists(repoDir, "origin", "nonexistent") || RemoteBranchExists(repoDir, "upstream", "feature-x") {
+		t.Error("expected unknown remote branches to not exist")
+	}
+}
+
+func TestAddWorktreeFromTracking(t *testing.T) {
+	repoDir := setupTestRepo(t)
+	setupRemote(t, repoDir, "feature-x")
+	if err := Fetch(repoDir, "origin"); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	wtPath := filepath.Join(t.TempDir(), "feature-x")
+	if err := AddWorktreeFrom(repoDir, wtPath, "feature-x", "origin/feature-x", true); err != nil {
+		t.Fatalf("AddWorktreeFrom failed: %v", err)
+	}
+
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "feature-x@{upstream}")
+	cmd.Dir = repoDir
+	out, err := cmd.Output()
+	if err != nil || string(out) != "origin/feature-x\n" {
+		t.Errorf("expected feature-x to track origin/feature-x, got %q (%v)", out, err)
+	}
+
+	head, err := ResolveRef(wtPath, "HEAD")
+	if err != nil {
+		t.Fatalf("ResolveRef failed: %v", err)
+	}
+	if remote, _ := ResolveRef(repoDir, "origin/feature-x"); head != remote {
+		t.Errorf("expected worktree at %s, got %s", remote, head)
+	}
+	if _, err := ResolveRef(repoDir, "no-such-ref"); err == nil {
+		t.Error("expected error for an unknown ref")
+	}
+}
+
+func TestParseWorktreeListLocked(t *testing.T) {
+	data := []byte(`worktree /path/to/main
+HEAD abc1234567890
+branch refs/heads/main
+
+worktree /path/to/usb
+HEAD def0987654321
+branch refs/heads/portable
+locked on removable drive
+
+worktree /path/to/other
+HEAD 0123456789abc
+branch refs/heads/other
+locked
+
+`)
+
+	worktrees := parseWorktreeList(data)
+	if len(worktrees) != 3 {
+		t.Fatalf("expected 3 worktrees, got %d", len(worktrees))
+	}
+	if worktrees[0].Locked {
+		t.Error("expected main worktree to be unlocked")
+	}
+	if !worktrees[1].Locked || worktrees[1].LockReason != "on removable drive" {
+		t.Errorf("expected lock with reason, got %+v", worktrees[1])
+	}
+	if !worktrees[2].Locked || worktrees[2].LockReason != "" {
+		t.Errorf("expected lock without reason, got %+v", worktrees[2])
+	}
+}
+
+func TestParseWorktreeListDetachedPrunable(t *testing.T) {
+	data := []byte(`worktree /path/to/main
+HEAD abc1234567890
+detached
+
+worktree /path/to/gone
+HEAD def0987654321
+branch refs/heads/gone
+prunable gitdir file points to non-existent location
+
+`)
+
+	worktrees := parseWorktreeList(data)
+	if len(worktrees) != 2 {
+		t.Fatalf("expected 2 worktrees, got %d", len(worktrees))
+	}
+	if !worktrees[0].Detached || worktrees[0].Branch != "" || worktrees[0].Prunable {
+		t.Errorf("expected detached worktree, got %+v", worktrees[0])
+	}
+	if worktrees[1].Detached || !worktrees[1].Prunable || worktrees[1].PrunableReason != "gitdir file points to non-existent location" {
+		t.Errorf("expected prunable worktree with reason, got %+v", worktrees[1])
+	}
+}
@@ -0,0 +1,209 @@
+// Package notify reports finished background jobs through the mechanisms
+// configured in tasks.post_install.notify.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Devdha/wm/internal/config"
+	"github.com/Devdha/wm/internal/runner"
+)
+
+// DefaultMessage is used when no message template is configured
+const DefaultMessage = "wm: {{.Task}} {{.Status}} in {{.Worktree}} ({{.Duration}})"
+
+const sendTimeout = 10 * time.Second
+
+// Event describes a finished job. Its fields are the template variables
+// available to notify.message and notify.command.
+type Event struct {
+	Worktree string        `json:"worktree"`
+	Task     string        `json:"task"`
+	Command  string        `json:"command"` // The command that failed, or the last one run
+	Status   string        `json:"status"`
+	ExitCode int           `json:"exit_code"`
+	Duration time.Duration `json:"-"`
+}
+
+// Options holds the job context needed to deliver notifications
+type Options struct {
+	Shell    string    // Shell for notify.command
+	Terminal io.Writer // Terminal for the bell and message; nil skips them
+}
+
+// FromJob builds the event for a finished job
+func FromJob(job *runner.Job) Event {
+	ev := Event{
+		Worktree: job.Dir,
+		Task:     job.Name,
+		Status:   job.Status(),
+		Duration: job.FinishedAt.Sub(job.StartedAt).Round(time.Millisecond),
+	}
+
+	for _, task := range job.Tasks {
+		if task.State == runner.StatePending || task.State == runner.StateSkipped {
+			break
+		}
+		ev.Command = task.Command.String()
+		ev.ExitCode = task.ExitCode
+		if task.State != runner.StateSucceeded {
+			break
+		}
+	}
+	return ev
+}
+
+// Send delivers ev through every mechanism enabled in cfg. A failing
+// mechanism does not prevent the others from running.
+func Send(cfg config.NotifyConfig, ev Event, opts Options) error {
+	if cfg.IsZero() {
+		return nil
+	}
+
+	msgTemplate := cfg.Message
+	if msgTemplate == "" {
+		msgTemplate = DefaultMessage
+	}
+	message, err := Render(msgTemplate, ev)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	if opts.Terminal != nil && (cfg.Bell || cfg.Message != "") {
+		errs = append(errs, notifyTerminal(opts.Terminal, message, cfg.Bell))
+	}
+	if cfg.Command != "" {
+		errs = append(errs, runCommand(cfg.Command, ev, opts.Shell))
+	}
+	if cfg.Webhook != "" {
+		errs = append(errs, postWebhook(cfg.Webhook, ev, message))
+	}
+	if cfg.Socket != "" {
+		errs = append(errs, writeSocket(cfg.Socket, ev, message))
+	}
+	return errors.Join(errs...)
+}
+
+// Render executes a notification template against ev
+func Render(text string, ev Event) (string, error) {
+	tmpl, err := template.New("notify").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid notify template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, ev); err != nil {
+		return "", fmt.Errorf("failed to render notify template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func notifyTerminal(w io.Writer, message string, bell bool) error {
+	out := "\n" + message + "\n"
+	if bell {
+		out = "\a" + out
+	}
+	if _, err := io.WriteString(w, out); err != nil {
+		return fmt.Errorf("failed to write to terminal: %w", err)
+	}
+	return nil
+}
+
+// runCommand runs notify.command with the event in WM_* variables. Template
+// values are inserted into the script unquoted, so commands and paths, which
+// may contain quotes, are safer read from the environment.
+func runCommand(text string, ev Event, shell string) error {
+	script, err := Render(text, ev)
+	if err != nil {
+		return err
+	}
+
+	argv := runner.Argv(config.Command{Run: script}, shell)
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Env = append(os.Environ(), eventEnv(ev)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("notify command failed: %w\n%s", err, out)
+	}
+	return nil
+}
+
+// eventEnv returns the WM_* variables describing ev
+func eventEnv(ev Event) []string {
+	return []string{
+		"WM_WORKTREE_PATH=" + ev.Worktree,
+		"WM_TASK=" + ev.Task,
+		"WM_COMMAND=" + ev.Command,
+		"WM_STATUS=" + ev.Status,
+		fmt.Sprintf("WM_EXIT_CODE=%d", ev.ExitCode),
+		"WM_DURATION=" + ev.Duration.String(),
+	}
+}
+
+// payload is the JSON document sent to webhooks and sockets
+type payload struct {
+	Event
+	DurationSeconds float64 `json:"duration_seconds"`
+	Message         string  `json:"message"`
+}
+
+func encodePayload(ev Event, message string) ([]byte, error) {
+	data, err := json.Marshal(payload{
+		Event:           ev,
+		DurationSeconds: ev.Duration.Seconds(),
+		Message:         strings.TrimSpace(message),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode notification: %w", err)
+	}
+	return data, nil
+}
+
+func postWebhook(url string, ev Event, message string) error {
+	data, err := encodePayload(ev, message)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: sendTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("webhook failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook failed: %s", resp.Status)
+	}
+	return nil
+}
+
+func writeSocket(path string, ev Event, message string) error {
+	data, err := encodePayload(ev, message)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialTimeout("unix", path, sendTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to notify socket: %w", err)
+	}
+	defer conn.Close()
+
+	conn.SetWriteDeadline(time.Now().Add(sendTimeout))
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write to notify socket: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package notify
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Devdha/wm/internal/config"
+	"github.com/Devdha/wm/internal/runner"
+)
+
+func testEvent() Event {
+	return Event{
+		Worktree: "/tmp/wm_repo/feature",
+		Task:     "post_install",
+		Command:  "pnpm install",
+		Status:   runner.StateSucceeded,
+		Duration: 3 * time.Second,
+	}
+}
+
+func TestFromJob(t *testing.T) {
+	start := time.Now()
+	job := &runner.Job{
+		Name:       "post_install",
+		Dir:        "/tmp/wt",
+		StartedAt:  start,
+		FinishedAt: start.Add(2 * time.Second),
+		Tasks: []runner.Task{
+			{Command: config.Command{Run: "pnpm install"}, State: runner.StateSucceeded},
+			{Command: config.Command{Run: "pnpm build"}, State: runner.StateFailed, ExitCode: 2},
+			{Command: config.Command{Run: "pnpm test"}, State: runner.StateSkipped},
+		},
+	}
+
+	ev := FromJob(job)
+	if ev.Status != runner.StateFailed || ev.ExitCode != 2 || ev.Command != "pnpm build" {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+	if ev.Duration != 2*time.Second {
+		t.Errorf("expected 2s duration, got %s", ev.Duration)
+	}
+}
+
+func TestRender(t *testing.T) {
+	got, err := Render(DefaultMessage, testEvent())
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	want := "wm: post_install succeeded in /tmp/wm_repo/feature (3s)"
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	if _, err := Render("{{.Unknown}}", testEvent()); err == nil {
+		t.Error("expected error for unknown template variable")
+	}
+}
+
+func TestSendTerminal(t *testing.T) {
+	var term bytes.Buffer
+	cfg := config.NotifyConfig{Message: "Ready: {{.Worktree}}", Bell: true}
+
+	if err := Send(cfg, testEvent(), Options{Terminal: &term}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if term.String() != "\a\nReady: /tmp/wm_repo/feature\n" {
+		t.Errorf("unexpected terminal output: %q", term.String())
+	}
+}
+
+func TestSendCommand(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "notified")
+	cfg := config.NotifyConfig{Command: "echo '{{.Task}} {{.ExitCode}}' > " + out}
+
+	if err := Send(cfg, testEvent(), Options{Shell: "sh"}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("notify command did not run: %v", err)
+	}
+	if string(content) != "post_install 0\n" {
+		t.Errorf("unexpected command output: %q", content)
+	}
+}
+
+func TestSendCommandEnv(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "notified")
+	cfg := config.NotifyConfig{Command: `printf '%s|%s|%s' "$WM_TASK" "$WM_STATUS" "$WM_COMMAND" > ` + out}
+
+	ev := testEvent()
+	ev.Command = `echo "it's done"; touch pwned`
+	if err := Send(cfg, ev, Options{Shell: "sh"}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("notify command did not run: %v", err)
+	}
+	want := "post_install|" + ev.Status + `|echo "it's done"; touch pwned`
+	if string(content) != want {
+		t.Errorf("expected %q, got %q", want, content)
+	}
+}
+
+func TestSendWebhook(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	cfg := config.NotifyConfig{Webhook: server.URL}
+	if err := Send(cfg, testEvent(), Options{}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if received["worktree"] != "/tmp/wm_repo/feature" || received["duration_seconds"] != 3.0 {
+		t.Errorf("unexpected webhook payload: %v", received)
+	}
+}
+
+func TestSendWebhookError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	if err := Send(config.NotifyConfig{Webhook: server.URL}, testEvent(), Options{}); err == nil {
+		t.Error("expected error for failing webhook")
+	}
+}
+
+func TestSendSocket(t *testing.T) {
+	sockPath := filepath.Join(t.TempDir(), "wm.sock")
+	ln, err := net.Listen("unix", sockPath)
+	if err != nil {
+		t.Skipf("unix sockets not available: %v", err)
+	}
+	defer ln.Close()
+
+	lines := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		lines <- line
+	}()
+
+	if err := Send(config.NotifyConfig{Socket: sockPath}, testEvent(), Options{}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	select {
+	case line := <-lines:
+		if !strings.Contains(line, `"task":"post_install"`) {
+			t.Errorf("unexpected socket payload: %s", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("socket did not receive a notification")
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package runner
+
+import "syscall"
+
+// detachedProcAttr starts the process in a new session, detached from the
+// controlling terminal and the caller's process group.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package runner
+
+import "syscall"
+
+const detachedProcess = 0x00000008 // DETACHED_PROCESS
+
+// detachedProcAttr starts the process without a console in a new process
+// group so it is not killed with the caller's console.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess,
+	}
+}
@@ -0,0 +1,348 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/Devdha/wm/internal/config"
+)
+
+// terminalFD is the descriptor under which the supervisor receives the
+// caller's terminal (the first of exec.Cmd.ExtraFiles)
+const terminalFD = 3
+
+// logPollInterval is how often CopyLog checks for new output when following
+const logPollInterval = 200 * time.Millisecond
+
+// RunJobCommand is the hidden wm subcommand that supervises a detached job
+const RunJobCommand = "__run-job"
+
+// Task states
+const (
+	StatePending   = "pending"
+	StateRunning   = "running"
+	StateSucceeded = "succeeded"
+	StateFailed    = "failed"
+	StateSkipped   = "skipped"
+	StateKilled    = "killed"
+)
+
+// Job is a list of commands run in the background by a detached wm process.
+// Its state is persisted as <dir>/<name>.json and its output is written to
+// <dir>/<name>.log.
+type Job struct {
+	Name        string              `json:"name"`
+	Dir         string              `json:"dir"` // Working directory of the commands
+	Shell       string              `json:"shell,omitempty"`
+	Env         []string            `json:"env,omitempty"` // Extra KEY=VALUE pairs for the commands
+	PID         int                 `json:"pid"`           // Supervisor PID, also its process group
+	Tasks       []Task              `json:"tasks"`
+	Notify      config.NotifyConfig `json:"notify,omitempty"`
+	HasTerminal bool                `json:"terminal,omitempty"` // The supervisor inherited the caller's terminal
+	StartedAt   time.Time           `json:"started_at"`
+	FinishedAt  time.Time           `json:"finished_at,omitempty"`
+}
+
+// Task is the state of one command of a Job
+type Task struct {
+	Command    config.Command `json:"command"`
+	State      string         `json:"state"`
+	PID        int            `json:"pid,omitempty"`
+	ExitCode   int            `json:"exit_code"`
+	Error      string         `json:"error,omitempty"`
+	StartedAt  time.Time      `json:"started_at,omitempty"`
+	FinishedAt time.Time      `json:"finished_at,omitempty"`
+}
+
+// NewJob creates a Job with every command pending
+func NewJob(name, dir, shell string, commands []config.Command) *Job {
+	job := &Job{Name: name, Dir: dir, Shell: shell}
+	for _, cmd := range commands {
+		if cmd.IsEmpty() {
+			continue
+		}
+		job.Tasks = append(job.Tasks, Task{Command: cmd, State: StatePending})
+	}
+	return job
+}
+
+// StatePath returns the path of a job's state file
+func StatePath(stateDir, name string) string {
+	return filepath.Join(stateDir, name+".json")
+}
+
+// LogPath returns the path of a job's log file
+func LogPath(stateDir, name string) string {
+	return filepath.Join(stateDir, name+".log")
+}
+
+// LoadJob reads a job's persisted state
+func LoadJob(stateDir, name string) (*Job, error) {
+	data, err := os.ReadFile(StatePath(stateDir, name))
+	if err != nil {
+		return nil, err
+	}
+
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to parse job state: %w", err)
+	}
+	return &job, nil
+}
+
+// Save atomically writes the job's state to stateDir
+func (j *Job) Save(stateDir string) error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal job state: %w", err)
+	}
+
+	path := StatePath(stateDir, j.Name)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write job state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write job state: %w", err)
+	}
+	return nil
+}
+
+// Terminal returns the caller's terminal inherited by the job supervisor,
+// or nil if there was none. It is only meaningful inside RunJobCommand.
+func (j *Job) Terminal() *os.File {
+	if !j.HasTerminal {
+		return nil
+	}
+	return os.NewFile(terminalFD, "terminal")
+}
+
+// Finished reports whether the job has stopped running
+func (j *Job) Finished() bool {
+	return !j.FinishedAt.IsZero()
+}
+
+// Reconcile marks the job as finished if its supervisor died without
+// recording a result, e.g. because the machine rebooted.
+func (j *Job) Reconcile() {
+	if j.Finished() || j.PID == 0 || processAlive(j.PID) {
+		return
+	}
+
+	now := time.Now()
+	for i := range j.Tasks {
+		task := &j.Tasks[i]
+		switch task.State {
+		case StateRunning:
+			task.State = StateFailed
+			task.ExitCode = -1
+			task.Error = "supervisor exited unexpectedly"
+			task.FinishedAt = now
+		case StatePending:
+			task.State = StateSkipped
+		}
+	}
+	j.FinishedAt = now
+}
+
+// Status summarises the job as the state of its most relevant task
+func (j *Job) Status() string {
+	status := StateSucceeded
+	for _, task := range j.Tasks {
+		switch task.State {
+		case StateRunning, StatePending:
+			return StateRunning
+		case StateFailed, StateKilled:
+			status = task.State
+		}
+	}
+	return status
+}
+
+// StartJob launches a detached wm process that runs the job's commands.
+// The process gets its own session so it survives the calling wm exiting,
+// and its output goes to the job's log file instead of the terminal.
+func StartJob(stateDir string, job *Job) error {
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate wm executable: %w", err)
+	}
+
+	logFile, err := os.Create(LogPath(stateDir, job.Name))
+	if err != nil {
+		return fmt.Errorf("failed to create log file: %w", err)
+	}
+	defer logFile.Close()
+
+	job.StartedAt = time.Now()
+	job.FinishedAt = time.Time{}
+
+	cmd := exec.Command(self, RunJobCommand, stateDir, job.Name)
+	cmd.Dir = job.Dir
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = detachedProcAttr()
+
+	// Hand over the terminal for notifications; without one they are skipped
+	if tty, err := openTerminal(); err == nil {
+		defer tty.Close()
+		cmd.ExtraFiles = []*os.File{tty}
+		job.HasTerminal = true
+	}
+
+	// Persist the initial state before the supervisor starts reading it
+	if err := job.Save(stateDir); err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start background job: %w", err)
+	}
+
+	// From here on only the supervisor writes the state file; it records
+	// its own PID, and saving here could overwrite a job that already finished
+	job.PID = cmd.Process.Pid
+	return cmd.Process.Release()
+}
+
+// RunJob executes a previously started job, writing command output to out
+// and persisting state after every transition. Commands run in order and
+// the first failure skips the remaining ones.
+func RunJob(stateDir, name string, out io.Writer) error {
+	job, err := LoadJob(stateDir, name)
+	if err != nil {
+		return err
+	}
+	job.PID = os.Getpid()
+	if err := job.Save(stateDir); err != nil {
+		return err
+	}
+
+	var failed error
+	for i := range job.Tasks {
+		task := &job.Tasks[i]
+		if failed != nil {
+			task.State = StateSkipped
+			continue
+		}
+
+		fmt.Fprintf(out, "==> %s\n", task.Command)
+		argv := Argv(task.Command, job.Shell)
+		cmd := exec.Command(argv[0], argv[1:]...)
+		cmd.Dir = job.Dir
+		cmd.Env = commandEnv(job.Env)
+		cmd.Stdout = out
+		cmd.Stderr = out
+
+		task.StartedAt = time.Now()
+		if err := cmd.Start(); err != nil {
+			task.State = StateFailed
+			task.ExitCode = -1
+			task.Error = err.Error()
+			task.FinishedAt = time.Now()
+			failed = err
+			continue
+		}
+
+		task.State = StateRunning
+		task.PID = cmd.Process.Pid
+		if err := job.Save(stateDir); err != nil {
+			fmt.Fprintf(out, "wm: %v\n", err)
+		}
+
+		err := cmd.Wait()
+		task.FinishedAt = time.Now()
+		task.ExitCode = cmd.ProcessState.ExitCode()
+		if err != nil {
+			task.State = StateFailed
+			task.Error = err.Error()
+			failed = err
+		} else {
+			task.State = StateSucceeded
+		}
+		fmt.Fprintf(out, "==> %s: %s (exit %d)\n", task.Command, task.State, task.ExitCode)
+	}
+
+	job.FinishedAt = time.Now()
+	if err := job.Save(stateDir); err != nil {
+		return err
+	}
+	if failed != nil {
+		return fmt.Errorf("job '%s' failed: %w", name, failed)
+	}
+	return nil
+}
+
+// KillJob terminates a running job and records its commands as killed
+func KillJob(stateDir, name string) (*Job, error) {
+	job, err := LoadJob(stateDir, name)
+	if err != nil {
+		return nil, err
+	}
+
+	job.Reconcile()
+	if job.Finished() {
+		return job, fmt.Errorf("job '%s' is not running", name)
+	}
+	if job.PID == 0 {
+		return job, fmt.Errorf("job '%s' has not started yet", name)
+	}
+
+	if err := killProcessGroup(job.PID); err != nil {
+		return job, fmt.Errorf("failed to kill job '%s': %w", name, err)
+	}
+
+	now := time.Now()
+	for i := range job.Tasks {
+		task := &job.Tasks[i]
+		switch task.State {
+		case StateRunning:
+			task.State = StateKilled
+			task.ExitCode = -1
+			task.FinishedAt = now
+		case StatePending:
+			task.State = StateSkipped
+		}
+	}
+	job.FinishedAt = now
+	return job, job.Save(stateDir)
+}
+
+// CopyLog writes a job's log to out. With follow it keeps waiting for new
+// output until the job finishes, like tail -f.
+func CopyLog(stateDir, name string, out io.Writer, follow bool) error {
+	f, err := os.Open(LogPath(stateDir, name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for {
+		if _, err := io.Copy(out, f); err != nil {
+			return fmt.Errorf("failed to read log: %w", err)
+		}
+		if !follow {
+			return nil
+		}
+
+		job, err := LoadJob(stateDir, name)
+		if err != nil {
+			return err
+		}
+		job.Reconcile()
+		if job.Finished() {
+			// Drain anything written between the copy and the state check
+			_, err := io.Copy(out, f)
+			return err
+		}
+		time.Sleep(logPollInterval)
+	}
+}
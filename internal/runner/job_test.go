@@ -0,0 +1,151 @@
+package runner
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/Devdha/wm/internal/config"
+)
+
+func TestRunJob(t *testing.T) {
+	stateDir := t.TempDir()
+	workDir := t.TempDir()
+
+	job := NewJob("post_install", workDir, "sh", []config.Command{
+		{Run: "echo first"},
+		{Run: ""},
+		{Args: []string{"sh", "-c", "echo second"}},
+	})
+	if len(job.Tasks) != 2 {
+		t.Fatalf("expected empty commands to be dropped, got %d tasks", len(job.Tasks))
+	}
+	if err := job.Save(stateDir); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := RunJob(stateDir, "post_install", &out); err != nil {
+		t.Fatalf("RunJob failed: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "first") || !strings.Contains(out.String(), "second") {
+		t.Errorf("expected command output, got: %s", out.String())
+	}
+
+	loaded, err := LoadJob(stateDir, "post_install")
+	if err != nil {
+		t.Fatalf("LoadJob failed: %v", err)
+	}
+	for _, task := range loaded.Tasks {
+		if task.State != StateSucceeded {
+			t.Errorf("expected task '%s' to succeed, got %s", task.Command, task.State)
+		}
+	}
+	if loaded.FinishedAt.IsZero() {
+		t.Error("expected job finish time to be recorded")
+	}
+}
+
+func TestRunJobRecordsPIDBeforeRunning(t *testing.T) {
+	stateDir := t.TempDir()
+	workDir := t.TempDir()
+
+	// The first command sees the state file as it was when it started
+	job := NewJob("post_install", workDir, "sh", []config.Command{
+		{Args: []string{"cp", StatePath(stateDir, "post_install"), "snapshot.json"}},
+	})
+	if err := job.Save(stateDir); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := RunJob(stateDir, "post_install", &out); err != nil {
+		t.Fatalf("RunJob failed: %v\n%s", err, out.String())
+	}
+
+	snapshot, err := LoadJob(workDir, "snapshot")
+	if err != nil {
+		t.Fatalf("failed to read snapshot: %v", err)
+	}
+	if snapshot.PID != os.Getpid() {
+		t.Errorf("expected supervisor PID %d to be saved before commands run, got %d", os.Getpid(), snapshot.PID)
+	}
+}
+
+func TestRunJobFailureSkipsRemaining(t *testing.T) {
+	stateDir := t.TempDir()
+
+	job := NewJob("post_install", t.TempDir(), "sh", []config.Command{
+		{Run: "exit 3"},
+		{Run: "echo never"},
+	})
+	if err := job.Save(stateDir); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := RunJob(stateDir, "post_install", &out); err == nil {
+		t.Fatal("expected RunJob to fail")
+	}
+
+	loaded, err := LoadJob(stateDir, "post_install")
+	if err != nil {
+		t.Fatalf("LoadJob failed: %v", err)
+	}
+	if loaded.Tasks[0].State != StateFailed || loaded.Tasks[0].ExitCode != 3 {
+		t.Errorf("expected first task failed with exit 3, got %s (%d)",
+			loaded.Tasks[0].State, loaded.Tasks[0].ExitCode)
+	}
+	if loaded.Tasks[1].State != StateSkipped {
+		t.Errorf("expected second task skipped, got %s", loaded.Tasks[1].State)
+	}
+}
+
+func TestReconcileDeadSupervisor(t *testing.T) {
+	// Use the PID of a process that has already exited
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	job := NewJob("post_install", t.TempDir(), "sh", []config.Command{
+		{Run: "sleep 10"},
+		{Run: "echo next"},
+	})
+	job.PID = cmd.Process.Pid
+	job.Tasks[0].State = StateRunning
+
+	if job.Status() != StateRunning {
+		t.Errorf("expected running status, got %s", job.Status())
+	}
+
+	job.Reconcile()
+
+	if !job.Finished() {
+		t.Fatal("expected job to be finished after reconcile")
+	}
+	if job.Tasks[0].State != StateFailed || job.Tasks[1].State != StateSkipped {
+		t.Errorf("unexpected task states: %s, %s", job.Tasks[0].State, job.Tasks[1].State)
+	}
+	if job.Status() != StateFailed {
+		t.Errorf("expected failed status, got %s", job.Status())
+	}
+}
+
+func TestCopyLog(t *testing.T) {
+	stateDir := t.TempDir()
+	if err := os.WriteFile(LogPath(stateDir, "post_install"), []byte("==> pnpm install\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := CopyLog(stateDir, "post_install", &out, false); err != nil {
+		t.Fatalf("CopyLog failed: %v", err)
+	}
+	if out.String() != "==> pnpm install\n" {
+		t.Errorf("unexpected log output: %q", out.String())
+	}
+}
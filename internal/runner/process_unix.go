@@ -0,0 +1,20 @@
+//go:build !windows
+
+package runner
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processAlive reports whether a process with the given PID exists
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// killProcessGroup terminates a job supervisor together with the commands
+// it started, which share its process group.
+func killProcessGroup(pid int) error {
+	return syscall.Kill(-pid, syscall.SIGTERM)
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package runner
+
+import "os"
+
+// processAlive reports whether a process with the given PID exists
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
+
+// killProcessGroup terminates a job supervisor. Windows has no process
+// group signal, so commands already started by it may keep running.
+func killProcessGroup(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
@@ -4,40 +4,95 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"runtime"
 	"strings"
+
+	"github.com/Devdha/wm/internal/config"
 )
 
-// RunCommands executes a list of commands in the specified directory
-func RunCommands(dir string, commands []string, background bool) error {
-	for _, cmdStr := range commands {
-		parts := strings.Fields(cmdStr)
-		if len(parts) == 0 {
+// Options controls how commands are executed
+type Options struct {
+	Shell string   // Shell for string commands, e.g. "bash -lc"; empty uses DefaultShell
+	Env   []string // Extra KEY=VALUE pairs added to the environment
+}
+
+// DefaultShell returns the user's $SHELL, falling back to sh (cmd on Windows)
+func DefaultShell() string {
+	if sh := os.Getenv("SHELL"); sh != "" {
+		return sh
+	}
+	if runtime.GOOS == "windows" {
+		return "cmd /C"
+	}
+	return "sh"
+}
+
+// Argv returns the argument vector used to execute cmd. String commands
+// are passed to the command's shell, or to shell if it has none.
+func Argv(cmd config.Command, shell string) []string {
+	if len(cmd.Args) > 0 {
+		return cmd.Args
+	}
+
+	if cmd.Shell != "" {
+		shell = cmd.Shell
+	}
+	if shell == "" {
+		shell = DefaultShell()
+	}
+
+	// "bash" and "bash -l" get -c; "bash -lc" or "cmd /C" are used as given
+	argv := strings.Fields(shell)
+	if len(argv) == 1 || !isCommandFlag(argv[len(argv)-1]) {
+		argv = append(argv, "-c")
+	}
+	return append(argv, cmd.Run)
+}
+
+// isCommandFlag reports whether arg makes a shell run the next argument as
+// a script: -c and combined flags ending in it such as -lc, or cmd's /C
+func isCommandFlag(arg string) bool {
+	switch {
+	case strings.EqualFold(arg, "/C"), arg == "-Command":
+		return true
+	case len(arg) < 2 || arg[0] != '-' || arg[1] == '-' || !strings.HasSuffix(arg, "c"):
+		return false
+	}
+	for _, r := range arg[1:] {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// RunCommands executes a list of commands in the specified directory,
+// stopping at the first failure
+func RunCommands(dir string, commands []config.Command, opts Options) error {
+	for _, command := range commands {
+		if command.IsEmpty() {
 			continue
 		}
 
-		cmd := exec.Command(parts[0], parts[1:]...)
+		argv := Argv(command, opts.Shell)
+		cmd := exec.Command(argv[0], argv[1:]...)
 		cmd.Dir = dir
-
-		if background {
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-			if err := cmd.Start(); err != nil {
-				return fmt.Errorf("failed to start command '%s': %w", cmdStr, err)
-			}
-			// Wait for completion in goroutine, print errors
-			go func(c *exec.Cmd, cmdStr string) {
-				if err := c.Wait(); err != nil {
-					fmt.Fprintf(os.Stderr, "Error: command '%s' failed: %v\n", cmdStr, err)
-				}
-			}(cmd, cmdStr)
-		} else {
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-			if err := cmd.Run(); err != nil {
-				return fmt.Errorf("command '%s' failed: %w", cmdStr, err)
-			}
+		cmd.Env = commandEnv(opts.Env)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("command '%s' failed: %w", command, err)
 		}
 	}
 
 	return nil
 }
+
+// commandEnv returns the environment for a command with extra added, or nil
+// to inherit the current environment unchanged
+func commandEnv(extra []string) []string {
+	if len(extra) == 0 {
+		return nil
+	}
+	return append(os.Environ(), extra...)
+}
@@ -0,0 +1,80 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Devdha/wm/internal/config"
+)
+
+func TestArgv(t *testing.T) {
+	tests := []struct {
+		name  string
+		cmd   config.Command
+		shell string
+		want  []string
+	}{
+		{"bare shell", config.Command{Run: "echo hi"}, "bash", []string{"bash", "-c", "echo hi"}},
+		{"shell with flags", config.Command{Run: "echo hi"}, "bash -lc", []string{"bash", "-lc", "echo hi"}},
+		{"shell flags without -c", config.Command{Run: "echo hi"}, "bash -l", []string{"bash", "-l", "-c", "echo hi"}},
+		{"shell option argument", config.Command{Run: "echo hi"}, "zsh -o pipefail", []string{"zsh", "-o", "pipefail", "-c", "echo hi"}},
+		{"long option", config.Command{Run: "echo hi"}, "bash --norc", []string{"bash", "--norc", "-c", "echo hi"}},
+		{"cmd", config.Command{Run: "echo hi"}, "cmd /C", []string{"cmd", "/C", "echo hi"}},
+		{"powershell", config.Command{Run: "echo hi"}, "pwsh -NoProfile -Command", []string{"pwsh", "-NoProfile", "-Command", "echo hi"}},
+		{"command shell wins", config.Command{Run: "echo hi", Shell: "zsh"}, "bash", []string{"zsh", "-c", "echo hi"}},
+		{"argv skips shell", config.Command{Args: []string{"go", "mod", "download"}}, "bash", []string{"go", "mod", "download"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Argv(tt.cmd, tt.shell)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestArgvDefaultShell(t *testing.T) {
+	t.Setenv("SHELL", "")
+
+	got := Argv(config.Command{Run: "true"}, "")
+	want := []string{"sh", "-c", "true"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestRunCommandsShellSyntax(t *testing.T) {
+	dir := t.TempDir()
+
+	cmds := []config.Command{
+		{Run: `GREETING="hello world" && echo "$GREETING" | tr a-z A-Z > out.txt`},
+		{Args: []string{"touch", "with space.txt"}},
+	}
+
+	if err := RunCommands(dir, cmds, Options{Shell: "sh"}); err != nil {
+		t.Fatalf("RunCommands failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if string(content) != "HELLO WORLD\n" {
+		t.Errorf("expected 'HELLO WORLD\\n', got '%s'", content)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "with space.txt")); err != nil {
+		t.Errorf("expected argv command to create file: %v", err)
+	}
+}
+
+func TestRunCommandsFailure(t *testing.T) {
+	err := RunCommands(t.TempDir(), []config.Command{{Run: "exit 3"}}, Options{Shell: "sh"})
+	if err == nil {
+		t.Error("expected error for failing command")
+	}
+}
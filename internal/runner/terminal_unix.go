@@ -0,0 +1,11 @@
+//go:build !windows
+
+package runner
+
+import "os"
+
+// openTerminal opens the caller's controlling terminal so a detached job can
+// still ring the bell or print a notification on it.
+func openTerminal() (*os.File, error) {
+	return os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package runner
+
+import (
+	"errors"
+	"os"
+)
+
+// openTerminal is not supported on Windows, where a detached process cannot
+// inherit extra handles.
+func openTerminal() (*os.File, error) {
+	return nil, errors.New("terminal inheritance is not supported on windows")
+}
@@ -0,0 +1,89 @@
+// Package shell provides the shell integration that lets wm change the
+// caller's working directory.
+//
+// A child process cannot change its parent's directory, so the wrapper
+// function emitted by Script passes a temporary file to wm through
+// CDFileEnv. Commands that want to move the user somewhere write the
+// target path to that file and the wrapper cds into it once wm exits.
+package shell
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// CDFileEnv names the environment variable the shell wrapper uses to hand
+// wm a file for the directory to change into.
+const CDFileEnv = "WM_CD_FILE"
+
+const posixScript = `# wm shell integration. Add this to your shell rc file:
+#   eval "$(wm shell-init %[1]s)"
+wm() {
+  local wm_cd_file wm_status
+  wm_cd_file="$(mktemp "${TMPDIR:-/tmp}/wm-cd.XXXXXX")" || return
+  WM_CD_FILE="$wm_cd_file" command wm "$@"
+  wm_status=$?
+  if [ -s "$wm_cd_file" ]; then
+    cd -- "$(cat "$wm_cd_file")" || wm_status=$?
+  fi
+  rm -f "$wm_cd_file"
+  return $wm_status
+}
+`
+
+const fishScript = `# wm shell integration. Add this to ~/.config/fish/config.fish:
+#   wm shell-init fish | source
+function wm --wraps wm --description 'Git worktree manager'
+    set -l wm_cd_file (mktemp)
+    or return
+    env WM_CD_FILE=$wm_cd_file wm $argv
+    set -l wm_status $status
+    if test -s $wm_cd_file
+        cd (cat $wm_cd_file)
+        or set wm_status $status
+    end
+    rm -f $wm_cd_file
+    return $wm_status
+end
+`
+
+var scripts = map[string]string{
+	"bash": fmt.Sprintf(posixScript, "bash"),
+	"zsh":  fmt.Sprintf(posixScript, "zsh"),
+	"fish": fishScript,
+}
+
+// Supported returns the names of shells Script can generate code for
+func Supported() []string {
+	names := make([]string, 0, len(scripts))
+	for name := range scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Script returns the wrapper function for the given shell
+func Script(name string) (string, error) {
+	script, ok := scripts[name]
+	if !ok {
+		return "", fmt.Errorf("unsupported shell '%s' (supported: %s)",
+			name, strings.Join(Supported(), ", "))
+	}
+	return script, nil
+}
+
+// RequestCD asks the shell wrapper to change into path after wm exits.
+// It returns false when wm is not running under the wrapper.
+func RequestCD(path string) (bool, error) {
+	file := os.Getenv(CDFileEnv)
+	if file == "" {
+		return false, nil
+	}
+	if err := os.WriteFile(file, []byte(path), 0600); err != nil {
+		return false, fmt.Errorf("failed to write cd target: %w", err)
+	}
+	return true, nil
+}
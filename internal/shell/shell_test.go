@@ -0,0 +1,56 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScript(t *testing.T) {
+	for _, name := range Supported() {
+		script, err := Script(name)
+		if err != nil {
+			t.Fatalf("Script(%s) failed: %v", name, err)
+		}
+		if !strings.Contains(script, CDFileEnv) {
+			t.Errorf("expected %s script to use %s", name, CDFileEnv)
+		}
+	}
+}
+
+func TestScriptUnsupported(t *testing.T) {
+	if _, err := Script("powershell"); err == nil {
+		t.Error("expected error for unsupported shell")
+	}
+}
+
+func TestRequestCD(t *testing.T) {
+	cdFile := filepath.Join(t.TempDir(), "cd")
+	t.Setenv(CDFileEnv, cdFile)
+
+	handled, err := RequestCD("/path/to/worktree")
+	if err != nil {
+		t.Fatalf("RequestCD failed: %v", err)
+	}
+	if !handled {
+		t.Fatal("expected RequestCD to be handled")
+	}
+
+	content, _ := os.ReadFile(cdFile)
+	if string(content) != "/path/to/worktree" {
+		t.Errorf("expected '/path/to/worktree', got '%s'", content)
+	}
+}
+
+func TestRequestCDWithoutWrapper(t *testing.T) {
+	t.Setenv(CDFileEnv, "")
+
+	handled, err := RequestCD("/path/to/worktree")
+	if err != nil {
+		t.Fatalf("RequestCD failed: %v", err)
+	}
+	if handled {
+		t.Error("expected RequestCD to be unhandled without the wrapper")
+	}
+}
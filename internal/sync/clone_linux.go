@@ -0,0 +1,39 @@
+//go:build linux
+
+package sync
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// ficlone is the FICLONE ioctl, which makes dst share src's data blocks on
+// filesystems such as btrfs and xfs
+const ficlone = 0x40049409
+
+// cloneFile creates dst as a copy-on-write clone of src
+func cloneFile(src, dst string) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	info, err := srcFile.Stat()
+	if err != nil {
+		return err
+	}
+
+	dstFile, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, dstFile.Fd(), ficlone, srcFile.Fd())
+	dstFile.Close()
+	if errno != 0 {
+		os.Remove(dst)
+		return fmt.Errorf("reflink not supported: %w", errno)
+	}
+	return nil
+}
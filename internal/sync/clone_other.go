@@ -0,0 +1,10 @@
+//go:build !linux
+
+package sync
+
+import "errors"
+
+// cloneFile is only implemented on Linux; elsewhere reflink falls back
+func cloneFile(src, dst string) error {
+	return errors.New("reflink not supported on this platform")
+}
@@ -0,0 +1,107 @@
+package sync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Devdha/wm/internal/config"
+)
+
+// Conflict policies for SyncItem.OnConflict
+const (
+	ConflictOverwrite = "overwrite" // Replace the local changes
+	ConflictSkip      = "skip"      // Keep the local changes
+	ConflictPrompt    = "prompt"    // Ask through Options.Resolve (default)
+	ConflictBackup    = "backup"    // Move the local copy aside, then replace it
+)
+
+// Conflict is a destination file that was edited since wm last synced it
+type Conflict struct {
+	Src  string // Relative to the source directory
+	Dst  string // Relative to the destination directory
+	Diff string // Unified diff from the local copy to the incoming content
+}
+
+// checkConflict reports how to proceed when dstPath exists and differs from
+// the incoming content. The destination conflicts only when the manifest
+// shows it changed since the last sync; files wm has no record of are
+// replaced as before. It returns ConflictSkip to keep the destination.
+func checkConflict(dstPath string, item config.SyncItem, incoming func() ([]byte, error), opts Options) (string, error) {
+	if item.Mode == "symlink" || item.Mode == "hardlink" {
+		return ConflictOverwrite, nil // The destination is the source
+	}
+
+	entry, ok := opts.Manifest.lookup(item.Dst)
+	if !ok || entry.Mode == "symlink" || entry.Mode == "hardlink" {
+		return ConflictOverwrite, nil // Links share their content with the source
+	}
+	info, err := os.Lstat(dstPath)
+	if err != nil || !info.Mode().IsRegular() {
+		return ConflictOverwrite, nil
+	}
+	if hash, err := hashFile(dstPath); err != nil || hash == entry.Hash {
+		return ConflictOverwrite, err
+	}
+
+	policy := item.OnConflict
+	if policy == "" {
+		policy = ConflictPrompt
+	}
+	if policy != ConflictPrompt {
+		return policy, nil
+	}
+	if opts.Resolve == nil {
+		return ConflictSkip, nil
+	}
+
+	local, err := os.ReadFile(dstPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", item.Dst, err)
+	}
+	content, err := incoming()
+	if err != nil {
+		return "", err
+	}
+	name := filepath.ToSlash(item.Dst)
+	return opts.Resolve(Conflict{
+		Src:  item.Src,
+		Dst:  item.Dst,
+		Diff: UnifiedDiff(local, content, "a/"+name+" (local)", "b/"+name),
+	}), nil
+}
+
+// backupFile moves path aside to a timestamped name and returns it
+func backupFile(path string) (string, error) {
+	backup := fmt.Sprintf("%s.wm-backup-%s", path, time.Now().Format("20060102-150405"))
+	if err := os.Rename(path, backup); err != nil {
+		return "", fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+	return backup, nil
+}
+
+// resolveConflict applies the item's conflict policy before dstPath is
+// replaced with incoming content. It returns false, with result marked as
+// a conflict, if the destination must be kept.
+func resolveConflict(dstPath string, item config.SyncItem, incoming func() ([]byte, error), opts Options, result *Result) (bool, error) {
+	policy, err := checkConflict(dstPath, item, incoming, opts)
+	if err != nil {
+		return false, err
+	}
+
+	switch policy {
+	case ConflictOverwrite:
+		return true, nil
+	case ConflictBackup:
+		backup, err := backupFile(dstPath)
+		if err != nil {
+			return false, err
+		}
+		result.Backup = item.Dst + backup[len(dstPath):]
+		return true, nil
+	default:
+		result.Status = StatusConflict
+		return false, nil
+	}
+}
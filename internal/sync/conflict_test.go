@@ -0,0 +1,154 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Devdha/wm/internal/config"
+)
+
+// setupConflict syncs .env once, then edits it on both sides
+func setupConflict(t *testing.T, policy string) (srcDir, dstDir string, items []config.SyncItem, manifest *Manifest) {
+	t.Helper()
+	srcDir, dstDir = t.TempDir(), t.TempDir()
+	manifest = NewManifest()
+	items = []config.SyncItem{{Src: ".env", Dst: ".env", Mode: "copy", When: "always", OnConflict: policy}}
+
+	if err := os.WriteFile(filepath.Join(srcDir, ".env"), []byte("SECRET=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SyncAll(srcDir, dstDir, items, Options{Manifest: manifest}); err != nil {
+		t.Fatalf("SyncAll failed: %v", err)
+	}
+	if _, ok := manifest.Files[".env"]; !ok {
+		t.Fatal("expected .env to be recorded in the manifest")
+	}
+
+	if err := os.WriteFile(filepath.Join(srcDir, ".env"), []byte("SECRET=2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dstDir, ".env"), []byte("SECRET=1\nDEBUG=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return srcDir, dstDir, items, manifest
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSyncConflictPolicies(t *testing.T) {
+	tests := []struct {
+		policy string
+		status Status
+		want   string
+	}{
+		{ConflictSkip, StatusConflict, "SECRET=1\nDEBUG=1\n"},
+		{ConflictOverwrite, StatusCopied, "SECRET=2\n"},
+		{ConflictBackup, StatusCopied, "SECRET=2\n"},
+		{ConflictPrompt, StatusConflict, "SECRET=1\nDEBUG=1\n"}, // No resolver
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			srcDir, dstDir, items, manifest := setupConflict(t, tt.policy)
+
+			results, err := SyncAll(srcDir, dstDir, items, Options{Manifest: manifest})
+			if err != nil {
+				t.Fatalf("SyncAll failed: %v", err)
+			}
+			if results[0].Status != tt.status {
+				t.Errorf("expected %s, got %s", tt.status, results[0].Status)
+			}
+			if got := readFile(t, filepath.Join(dstDir, ".env")); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+
+			if tt.policy == ConflictBackup {
+				if results[0].Backup == "" {
+					t.Fatal("expected a backup path")
+				}
+				if got := readFile(t, filepath.Join(dstDir, results[0].Backup)); got != "SECRET=1\nDEBUG=1\n" {
+					t.Errorf("expected backup of local edits, got %q", got)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncConflictPrompt(t *testing.T) {
+	srcDir, dstDir, items, manifest := setupConflict(t, "")
+
+	var seen Conflict
+	resolve := func(c Conflict) string {
+		seen = c
+		return ConflictOverwrite
+	}
+	results, err := SyncAll(srcDir, dstDir, items, Options{Manifest: manifest, Resolve: resolve})
+	if err != nil {
+		t.Fatalf("SyncAll failed: %v", err)
+	}
+
+	if seen.Dst != ".env" || !strings.Contains(seen.Diff, "-DEBUG=1") || !strings.Contains(seen.Diff, "+SECRET=2") {
+		t.Errorf("unexpected conflict %+v", seen)
+	}
+	if results[0].Status != StatusCopied {
+		t.Errorf("expected copied, got %s", results[0].Status)
+	}
+
+	// The manifest now matches the new content, so the next edit-free sync
+	// is not a conflict
+	if err := os.WriteFile(filepath.Join(srcDir, ".env"), []byte("SECRET=3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	results, err = SyncAll(srcDir, dstDir, items, Options{Manifest: manifest})
+	if err != nil || results[0].Status != StatusCopied {
+		t.Errorf("expected copied without conflict, got %v (%v)", results, err)
+	}
+}
+
+func TestSyncWithoutRecordOverwrites(t *testing.T) {
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(srcDir, ".env"), []byte("NEW=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dstDir, ".env"), []byte("UNKNOWN=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	items := []config.SyncItem{{Src: ".env", Dst: ".env", Mode: "copy", When: "always"}}
+	results, err := SyncAll(srcDir, dstDir, items, Options{Manifest: NewManifest()})
+	if err != nil || results[0].Status != StatusCopied {
+		t.Errorf("expected files without a record to be replaced, got %v (%v)", results, err)
+	}
+}
+
+func TestManifestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wm", "sync.json")
+
+	m, err := LoadManifest(path)
+	if err != nil || len(m.Files) != 0 {
+		t.Fatalf("expected empty manifest, got %v (%v)", m, err)
+	}
+
+	m.Files["apps/web/.env"] = ManifestEntry{Src: "apps/web/.env", Hash: "abc"}
+	if err := m.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadManifest(path)
+	if err != nil {
+		t.Fatalf("LoadManifest failed: %v", err)
+	}
+	if loaded.Files["apps/web/.env"].Hash != "abc" {
+		t.Errorf("unexpected manifest %+v", loaded)
+	}
+}
@@ -0,0 +1,150 @@
+package sync
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// maxDiffCells bounds the size of the line comparison table, so diffing
+// huge files degrades to a one-line note instead of exhausting memory
+const maxDiffCells = 4 << 20
+
+type diffOp struct {
+	kind byte // ' ' unchanged, '-' removed, '+' added
+	text string
+}
+
+// UnifiedDiff returns a unified diff that turns a into b, or "" if they are
+// equal. Binary and very large files are only reported as different.
+func UnifiedDiff(a, b []byte, nameA, nameB string) string {
+	if bytes.Equal(a, b) {
+		return ""
+	}
+	if bytes.IndexByte(a, 0) >= 0 || bytes.IndexByte(b, 0) >= 0 {
+		return fmt.Sprintf("Binary files %s and %s differ\n", nameA, nameB)
+	}
+
+	linesA, linesB := splitLines(a), splitLines(b)
+	if (len(linesA)+1)*(len(linesB)+1) > maxDiffCells {
+		return fmt.Sprintf("Files %s and %s differ (too large to diff)\n", nameA, nameB)
+	}
+
+	ops := diffLines(linesA, linesB)
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", nameA, nameB)
+	writeHunks(&out, ops)
+	return out.String()
+}
+
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes a shortest edit script through the longest common
+// subsequence of a and b
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// writeHunks prints the changes in ops with diffContext lines around them,
+// merging changes whose context overlaps
+func writeHunks(out *strings.Builder, ops []diffOp) {
+	// Line numbers in a and b at the start of each op
+	lineA := make([]int, len(ops)+1)
+	lineB := make([]int, len(ops)+1)
+	for k, op := range ops {
+		lineA[k+1], lineB[k+1] = lineA[k], lineB[k]
+		if op.kind != '+' {
+			lineA[k+1]++
+		}
+		if op.kind != '-' {
+			lineB[k+1]++
+		}
+	}
+
+	for k := 0; k < len(ops); {
+		if ops[k].kind == ' ' {
+			k++
+			continue
+		}
+
+		start := max(0, k-diffContext)
+		end := k
+		for next := k; next < len(ops) && next <= end+2*diffContext; next++ {
+			if ops[next].kind != ' ' {
+				end = next
+			}
+		}
+		end = min(len(ops), end+diffContext+1)
+
+		fmt.Fprintf(out, "@@ -%s +%s @@\n",
+			hunkRange(lineA[start], lineA[end]-lineA[start]),
+			hunkRange(lineB[start], lineB[end]-lineB[start]))
+		for _, op := range ops[start:end] {
+			out.WriteByte(op.kind)
+			out.WriteString(op.text)
+			if !strings.HasSuffix(op.text, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		k = end
+	}
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
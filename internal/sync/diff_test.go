@@ -0,0 +1,51 @@
+package sync
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "A=1\nB=2\nC=3\nD=4\nE=5\nF=6\nG=7\nH=8\nI=9\n"
+	b := "A=1\nB=two\nC=3\nD=4\nE=5\nF=6\nG=7\nH=8\nI=9\nJ=10\n"
+
+	want := `--- a/.env
++++ b/.env
+@@ -1,5 +1,5 @@
+ A=1
+-B=2
++B=two
+ C=3
+ D=4
+ E=5
+@@ -7,3 +7,4 @@
+ G=7
+ H=8
+ I=9
++J=10
+`
+	if got := UnifiedDiff([]byte(a), []byte(b), "a/.env", "b/.env"); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestUnifiedDiffEdgeCases(t *testing.T) {
+	if got := UnifiedDiff([]byte("same\n"), []byte("same\n"), "a", "b"); got != "" {
+		t.Errorf("expected no diff for equal input, got %q", got)
+	}
+
+	got := UnifiedDiff(nil, []byte("NEW=1\n"), "/dev/null", "b/.env")
+	if !strings.Contains(got, "@@ -0,0 +1 @@\n+NEW=1\n") {
+		t.Errorf("unexpected diff for new file: %q", got)
+	}
+
+	got = UnifiedDiff([]byte("X=1"), []byte("X=2"), "a", "b")
+	if !strings.Contains(got, "-X=1\n\\ No newline at end of file\n+X=2\n") {
+		t.Errorf("unexpected diff without trailing newline: %q", got)
+	}
+
+	got = UnifiedDiff([]byte("a\x00"), []byte("b\x00"), "a", "b")
+	if got != "Binary files a and b differ\n" {
+		t.Errorf("unexpected diff for binary files: %q", got)
+	}
+}
@@ -0,0 +1,152 @@
+package sync
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Devdha/wm/internal/config"
+)
+
+// SyncPath syncs item whether its source is a file or a directory. A
+// directory is copied file by file, or linked as a whole with mode: symlink.
+// With exclude patterns a symlinked directory is recreated with one link
+// per file instead, since a single link cannot leave anything out.
+func SyncPath(srcDir, dstDir string, item config.SyncItem) ([]Result, error) {
+	return syncPath(srcDir, dstDir, item, Options{})
+}
+
+func syncPath(srcDir, dstDir string, item config.SyncItem, opts Options) ([]Result, error) {
+	info, err := os.Stat(filepath.Join(srcDir, item.Src))
+	if err != nil || !info.IsDir() || (item.Mode == "symlink" && len(item.Exclude) == 0) {
+		result, err := syncFile(srcDir, dstDir, item, opts)
+		return []Result{result}, err
+	}
+	return syncDir(srcDir, dstDir, item, opts)
+}
+
+func syncDir(srcDir, dstDir string, item config.SyncItem, opts Options) ([]Result, error) {
+	srcRoot := filepath.Join(srcDir, item.Src)
+	dstRoot := filepath.Join(dstDir, item.Dst)
+
+	// Replace a whole-directory link left by an earlier mode: symlink
+	if info, err := os.Lstat(dstRoot); err == nil && !info.IsDir() {
+		if item.When == "missing" {
+			return []Result{{Src: item.Src, Dst: item.Dst, Status: StatusSkippedExists}}, nil
+		}
+		if err := os.Remove(dstRoot); err != nil {
+			return nil, fmt.Errorf("failed to replace %s: %w", dstRoot, err)
+		}
+	}
+
+	var results []Result
+	var dirs []string
+	err := filepath.WalkDir(srcRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, _ := filepath.Rel(srcRoot, path)
+		if rel != "." && isExcluded(rel, item.Exclude) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		sub := item
+		sub.Src = filepath.Join(item.Src, rel)
+		sub.Dst = filepath.Join(item.Dst, rel)
+
+		switch {
+		case d.IsDir():
+			if err := os.MkdirAll(filepath.Join(dstDir, sub.Dst), 0755); err != nil {
+				return fmt.Errorf("failed to create dest directory: %w", err)
+			}
+			dirs = append(dirs, rel)
+			return nil
+		case d.Type()&fs.ModeSymlink != 0:
+			result, err := copySymlink(srcDir, dstDir, sub)
+			results = append(results, result)
+			return err
+		case d.Type().IsRegular():
+			result, err := syncFile(srcDir, dstDir, sub, opts)
+			results = append(results, result)
+			return err
+		}
+		return nil // Sockets, devices and pipes are not synced
+	})
+	if err != nil {
+		return results, err
+	}
+
+	// Apply directory modes last so read-only directories can be filled
+	// first. The owner keeps write access, or the next sync could not
+	// update them.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		info, err := os.Stat(filepath.Join(srcRoot, dirs[i]))
+		if err != nil {
+			continue
+		}
+		if err := os.Chmod(filepath.Join(dstRoot, dirs[i]), info.Mode().Perm()|0200); err != nil {
+			return results, fmt.Errorf("failed to set directory mode: %w", err)
+		}
+	}
+	return results, nil
+}
+
+// copySymlink recreates a symlink found inside a synced directory with the
+// same target, so relative links keep pointing inside the copied tree
+func copySymlink(srcDir, dstDir string, item config.SyncItem) (Result, error) {
+	srcPath := filepath.Join(srcDir, item.Src)
+	dstPath := filepath.Join(dstDir, item.Dst)
+	result := Result{Src: item.Src, Dst: item.Dst}
+
+	target, err := os.Readlink(srcPath)
+	if err != nil {
+		return result, fmt.Errorf("failed to read symlink: %w", err)
+	}
+
+	if _, err := os.Lstat(dstPath); err == nil {
+		if item.When == "missing" {
+			result.Status = StatusSkippedExists
+			return result, nil
+		}
+		if existing, err := os.Readlink(dstPath); err == nil && existing == target {
+			result.Status = StatusUnchanged
+			return result, nil
+		}
+		os.Remove(dstPath)
+	}
+
+	if err := os.Symlink(target, dstPath); err != nil {
+		return result, fmt.Errorf("failed to create symlink: %w", err)
+	}
+	result.Status = StatusLinked
+	return result, nil
+}
+
+// isExcluded reports whether rel, a slash or OS separated path inside a
+// synced directory, matches one of the patterns. Patterns are matched
+// against the whole relative path and against each path component, so
+// "*.log" and "cache" exclude matching entries at any depth.
+func isExcluded(rel string, patterns []string) bool {
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+		if strings.Contains(pattern, "/") {
+			continue
+		}
+		for _, part := range strings.Split(rel, "/") {
+			if ok, _ := filepath.Match(pattern, part); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Devdha/wm/internal/config"
+)
+
+// setupDirTree creates certs/ with a nested file, an executable, a
+// relative symlink and a log file
+func setupDirTree(t *testing.T) string {
+	t.Helper()
+	srcDir := t.TempDir()
+
+	files := map[string]string{
+		"certs/dev.pem":          "CERT",
+		"certs/nested/key.pem":   "KEY",
+		"certs/tools/renew.sh":   "#!/bin/sh",
+		"certs/debug.log":        "LOG",
+		"certs/cache/blob.bin":   "BLOB",
+		"certs/nested/trace.log": "TRACE",
+	}
+	for rel, content := range files {
+		path := filepath.Join(srcDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chmod(filepath.Join(srcDir, "certs/tools/renew.sh"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(srcDir, "certs/nested"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("dev.pem", filepath.Join(srcDir, "certs/current.pem")); err != nil {
+		t.Fatal(err)
+	}
+	return srcDir
+}
+
+func TestSyncDirCopy(t *testing.T) {
+	srcDir := setupDirTree(t)
+	dstDir := t.TempDir()
+
+	item := config.SyncItem{
+		Src:     "certs",
+		Dst:     "certs",
+		Mode:    "copy",
+		When:    "always",
+		Exclude: []string{"*.log", "cache"},
+	}
+
+	results, err := SyncPath(srcDir, dstDir, item)
+	if err != nil {
+		t.Fatalf("SyncPath failed: %v", err)
+	}
+	if len(results) != 4 {
+		t.Errorf("expected 4 results, got %d: %v", len(results), results)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dstDir, "certs/nested/key.pem"))
+	if err != nil || string(content) != "KEY" {
+		t.Errorf("expected nested file to be copied, got %q (%v)", content, err)
+	}
+
+	info, err := os.Stat(filepath.Join(dstDir, "certs/tools/renew.sh"))
+	if err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Errorf("expected executable mode to be preserved: %v", err)
+	}
+
+	info, err = os.Stat(filepath.Join(dstDir, "certs/nested"))
+	if err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("expected directory mode 0700 to be preserved, got %v (%v)", info.Mode().Perm(), err)
+	}
+
+	target, err := os.Readlink(filepath.Join(dstDir, "certs/current.pem"))
+	if err != nil || target != "dev.pem" {
+		t.Errorf("expected relative symlink to be recreated, got %q (%v)", target, err)
+	}
+
+	for _, excluded := range []string{"certs/debug.log", "certs/nested/trace.log", "certs/cache"} {
+		if _, err := os.Lstat(filepath.Join(dstDir, excluded)); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be excluded", excluded)
+		}
+	}
+
+	// A second sync changes nothing
+	results, err = SyncPath(srcDir, dstDir, item)
+	if err != nil {
+		t.Fatalf("SyncPath failed: %v", err)
+	}
+	for _, r := range results {
+		if r.Status != StatusUnchanged {
+			t.Errorf("expected %s to be unchanged, got %s", r.Dst, r.Status)
+		}
+	}
+}
+
+func TestSyncDirReadOnly(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+	roDir := filepath.Join(srcDir, "vendor")
+	if err := os.MkdirAll(roDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(roDir, "a.txt"), []byte("A"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(roDir, 0555); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Chmod(roDir, 0755)
+		os.Chmod(filepath.Join(dstDir, "vendor"), 0755)
+	})
+
+	item := config.SyncItem{Src: "vendor", Dst: "vendor", Mode: "copy", When: "always"}
+	if _, err := SyncPath(srcDir, dstDir, item); err != nil {
+		t.Fatalf("SyncPath failed: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(dstDir, "vendor"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("expected read-only directory to stay writable by its owner, got %v", info.Mode().Perm())
+	}
+
+	// A file added to the source later must reach the copy
+	if err := os.Chmod(roDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(roDir, "b.txt"), []byte("B"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(roDir, 0555); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SyncPath(srcDir, dstDir, item); err != nil {
+		t.Fatalf("re-sync failed: %v", err)
+	}
+	if content, err := os.ReadFile(filepath.Join(dstDir, "vendor", "b.txt")); err != nil || string(content) != "B" {
+		t.Errorf("expected b.txt in the copy, got %q (%v)", content, err)
+	}
+}
+
+func TestSyncDirSymlink(t *testing.T) {
+	srcDir := setupDirTree(t)
+	dstDir := t.TempDir()
+
+	item := config.SyncItem{Src: "certs", Dst: "certs", Mode: "symlink", When: "always"}
+	results, err := SyncPath(srcDir, dstDir, item)
+	if err != nil {
+		t.Fatalf("SyncPath failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != StatusLinked {
+		t.Errorf("expected a single linked result, got %v", results)
+	}
+
+	info, err := os.Lstat(filepath.Join(dstDir, "certs"))
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("expected the whole directory to be a symlink: %v", err)
+	}
+}
+
+func TestSyncDirSymlinkWithExclude(t *testing.T) {
+	srcDir := setupDirTree(t)
+	dstDir := t.TempDir()
+
+	item := config.SyncItem{Src: "certs", Dst: "certs", Mode: "symlink", When: "always", Exclude: []string{"*.log"}}
+	if _, err := SyncPath(srcDir, dstDir, item); err != nil {
+		t.Fatalf("SyncPath failed: %v", err)
+	}
+
+	info, err := os.Lstat(filepath.Join(dstDir, "certs"))
+	if err != nil || !info.IsDir() {
+		t.Fatalf("expected a real directory: %v", err)
+	}
+
+	info, err = os.Lstat(filepath.Join(dstDir, "certs/nested/key.pem"))
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("expected files to be linked individually: %v", err)
+	}
+
+	if _, err := os.Lstat(filepath.Join(dstDir, "certs/debug.log")); !os.IsNotExist(err) {
+		t.Error("expected debug.log to be excluded")
+	}
+}
+
+func TestIsExcluded(t *testing.T) {
+	tests := []struct {
+		rel      string
+		patterns []string
+		want     bool
+	}{
+		{"debug.log", []string{"*.log"}, true},
+		{"nested/trace.log", []string{"*.log"}, true},
+		{"cache/blob.bin", []string{"cache/"}, true},
+		{"nested/key.pem", []string{"nested/*.pem"}, true},
+		{"other/nested/key.pem", []string{"nested/*.pem"}, false},
+		{"dev.pem", []string{"*.log"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := isExcluded(tt.rel, tt.patterns); got != tt.want {
+			t.Errorf("isExcluded(%q, %v) = %v, want %v", tt.rel, tt.patterns, got, tt.want)
+		}
+	}
+}
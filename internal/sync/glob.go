@@ -0,0 +1,119 @@
+package sync
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// isNegation reports whether a sync source is a "!pattern" exclusion
+func isNegation(src string) bool {
+	return strings.HasPrefix(src, "!")
+}
+
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// matchPattern reports whether the slash separated path matches pattern.
+// "**" matches zero or more whole path components; other components use
+// path.Match semantics.
+func matchPattern(pattern, name string) bool {
+	return matchParts(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchParts(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse consecutive ** and try every possible split
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := range name {
+				if matchParts(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// expandPattern returns the paths under srcDir that match pattern. Patterns
+// without "**" use filepath.Glob; recursive patterns walk from the deepest
+// directory without wildcards, skipping directories named in ignoreDirs
+// unless the pattern names them explicitly.
+func expandPattern(srcDir, pattern string, ignoreDirs []string) ([]string, error) {
+	pattern = filepath.ToSlash(pattern)
+	if !strings.Contains(pattern, "**") {
+		return filepath.Glob(filepath.Join(srcDir, filepath.FromSlash(pattern)))
+	}
+
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	explicit := make(map[string]bool)
+	for _, part := range strings.Split(pattern, "/") {
+		explicit[part] = true
+	}
+	ignored := make(map[string]bool)
+	for _, dir := range ignoreDirs {
+		if !explicit[dir] {
+			ignored[dir] = true
+		}
+	}
+
+	walkRoot := filepath.Join(srcDir, staticPrefix(filepath.FromSlash(pattern)))
+	var matches []string
+	err := filepath.WalkDir(walkRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == walkRoot {
+				return fs.SkipAll // Nothing to match under a missing prefix
+			}
+			return nil
+		}
+
+		if d.IsDir() && p != walkRoot && ignored[d.Name()] {
+			return filepath.SkipDir
+		}
+
+		rel, _ := filepath.Rel(srcDir, p)
+		if rel != "." && matchPattern(pattern, filepath.ToSlash(rel)) {
+			matches = append(matches, p)
+			if d.IsDir() {
+				return filepath.SkipDir // The directory is synced as a whole
+			}
+		}
+		return nil
+	})
+	return matches, err
+}
+
+// negatedBy reports whether rel, or a directory containing it, is excluded
+// by one of the "!pattern" sources
+func negatedBy(rel string, negations []string) bool {
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for _, neg := range negations {
+		pattern := strings.TrimSuffix(filepath.ToSlash(strings.TrimPrefix(neg, "!")), "/")
+		for i := 1; i <= len(parts); i++ {
+			if matchPattern(pattern, strings.Join(parts[:i], "/")) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/Devdha/wm/internal/config"
+)
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"**/.env.local", ".env.local", true},
+		{"**/.env.local", "apps/web/.env.local", true},
+		{"**/.env.local", "apps/web/.env", false},
+		{"apps/**/.env", "apps/.env", true},
+		{"apps/**/.env", "apps/web/nested/.env", true},
+		{"apps/**/.env", "libs/web/.env", false},
+		{"apps/*/.env", "apps/web/nested/.env", false},
+		{"apps/**", "apps/web/.env", true},
+		{"**/*.pem", "certs/dev.pem", true},
+	}
+
+	for _, tt := range tests {
+		if got := matchPattern(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchPattern(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func setupMonorepo(t *testing.T) string {
+	t.Helper()
+	srcDir := t.TempDir()
+
+	for _, rel := range []string{
+		".env.local",
+		"apps/web/.env.local",
+		"apps/api/nested/.env.local",
+		"apps/legacy/.env.local",
+		"node_modules/pkg/.env.local",
+		"target/debug/.env.local",
+	} {
+		path := filepath.Join(srcDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(rel), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return srcDir
+}
+
+func relMatches(t *testing.T, srcDir string, matches []string) []string {
+	t.Helper()
+	var rels []string
+	for _, m := range matches {
+		rel, _ := filepath.Rel(srcDir, m)
+		rels = append(rels, filepath.ToSlash(rel))
+	}
+	sort.Strings(rels)
+	return rels
+}
+
+func TestExpandPatternIgnoreDirs(t *testing.T) {
+	srcDir := setupMonorepo(t)
+	ignore := []string{".git", "node_modules", "target"}
+
+	matches, err := expandPattern(srcDir, "**/.env.local", ignore)
+	if err != nil {
+		t.Fatalf("expandPattern failed: %v", err)
+	}
+	got := relMatches(t, srcDir, matches)
+	want := []string{".env.local", "apps/api/nested/.env.local", "apps/legacy/.env.local", "apps/web/.env.local"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+
+	// Naming an ignored directory explicitly still descends into it
+	matches, err = expandPattern(srcDir, "node_modules/**/.env.local", ignore)
+	if err != nil {
+		t.Fatalf("expandPattern failed: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Errorf("expected explicit node_modules match, got %v", matches)
+	}
+}
+
+func TestSyncAllNegation(t *testing.T) {
+	srcDir := setupMonorepo(t)
+	dstDir := t.TempDir()
+
+	items := []config.SyncItem{
+		{Src: "**/.env.local", Dst: "**/.env.local", Mode: "copy", When: "always"},
+		{Src: "!apps/legacy"},
+	}
+
+	results, err := SyncAll(srcDir, dstDir, items, Options{IgnoreDirs: []string{"node_modules", "target"}})
+	if err != nil {
+		t.Fatalf("SyncAll failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Errorf("expected 3 results, got %v", results)
+	}
+
+	if _, err := os.Stat(filepath.Join(dstDir, "apps/api/nested/.env.local")); err != nil {
+		t.Errorf("expected nested file to be synced: %v", err)
+	}
+	for _, excluded := range []string{"apps/legacy/.env.local", "node_modules/pkg/.env.local", "target/debug/.env.local"} {
+		if _, err := os.Stat(filepath.Join(dstDir, excluded)); !os.IsNotExist(err) {
+			t.Errorf("expected %s not to be synced", excluded)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package sync
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Devdha/wm/internal/config"
+	"github.com/Devdha/wm/internal/git"
+)
+
+// ignoredSources returns the paths, relative to srcDir, that an
+// untracked_ignored item syncs: everything git ignores but finds on disk,
+// filtered by the item's include and exclude patterns. Directories in
+// ignoreDirs are skipped unless an include pattern names them.
+func ignoredSources(srcDir string, item config.SyncItem, ignoreDirs []string) ([]string, error) {
+	entries, err := git.ListIgnoredFiles(srcDir)
+	if err != nil {
+		return nil, err
+	}
+
+	heavy := heavyDirs(item.Include, ignoreDirs)
+	var sources []string
+	for _, entry := range entries {
+		rel := filepath.FromSlash(strings.TrimSuffix(entry, "/"))
+		if isHeavy(rel, heavy) || matchesFilter(rel, item.Exclude) {
+			continue
+		}
+
+		if len(item.Include) == 0 || matchesFilter(rel, item.Include) {
+			sources = append(sources, rel)
+			continue
+		}
+
+		// Wholly ignored directories are listed once; look inside them for
+		// files the include patterns pick out
+		if !strings.HasSuffix(entry, "/") {
+			continue
+		}
+		filepath.WalkDir(filepath.Join(srcDir, rel), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			sub, _ := filepath.Rel(srcDir, path)
+			if d.IsDir() {
+				if sub != rel && (heavy[d.Name()] || matchesFilter(sub, item.Exclude)) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if matchesFilter(sub, item.Include) && !matchesFilter(sub, item.Exclude) {
+				sources = append(sources, sub)
+			}
+			return nil
+		})
+	}
+	return sources, nil
+}
+
+// expandIgnored resolves an untracked_ignored item to one item per source.
+// Each source keeps its path relative to the repo root in the destination.
+func expandIgnored(srcDir string, item config.SyncItem, negations []string, opts Options) ([]config.SyncItem, error) {
+	sources, err := ignoredSources(srcDir, item, opts.IgnoreDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Heavy directories nested inside a copied directory are left out too
+	exclude := append([]string{}, item.Exclude...)
+	for dir := range heavyDirs(item.Include, opts.IgnoreDirs) {
+		exclude = append(exclude, dir)
+	}
+
+	var expanded []config.SyncItem
+	for _, rel := range sources {
+		if negatedBy(rel, negations) || isNestedRepo(filepath.Join(srcDir, rel)) {
+			continue
+		}
+		sub := item
+		sub.UntrackedIgnored = false
+		sub.Include = nil
+		sub.Src, sub.Dst = rel, rel
+		sub.Exclude = exclude
+		expanded = append(expanded, sub)
+	}
+	return expanded, nil
+}
+
+// heavyDirs returns the directory names from ignoreDirs that are not named
+// by any include pattern
+func heavyDirs(include, ignoreDirs []string) map[string]bool {
+	explicit := make(map[string]bool)
+	for _, pattern := range include {
+		for _, part := range strings.Split(filepath.ToSlash(pattern), "/") {
+			explicit[part] = true
+		}
+	}
+
+	heavy := make(map[string]bool)
+	for _, dir := range ignoreDirs {
+		if !explicit[dir] {
+			heavy[dir] = true
+		}
+	}
+	return heavy
+}
+
+func isHeavy(rel string, heavy map[string]bool) bool {
+	for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+		if heavy[part] {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesFilter reports whether rel, or a directory containing it, matches
+// one of patterns. Patterns without a slash match any single component.
+func matchesFilter(rel string, patterns []string) bool {
+	return isExcluded(rel, patterns) || negatedBy(rel, patterns)
+}
+
+// isNestedRepo reports whether dir is a separate git checkout, such as a
+// worktree placed inside the repository, which must not be copied
+func isNestedRepo(dir string) bool {
+	_, err := os.Lstat(filepath.Join(dir, ".git"))
+	return err == nil
+}
@@ -0,0 +1,113 @@
+package sync
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/Devdha/wm/internal/config"
+)
+
+func setupIgnoredRepo(t *testing.T) string {
+	t.Helper()
+	srcDir := t.TempDir()
+
+	files := map[string]string{
+		".gitignore":                 ".env*\n*.local.json\nnode_modules/\n.cache/\n",
+		".env":                       "SECRET=1",
+		"apps/web/.env.local":        "WEB=1",
+		"config/dev.local.json":      "{}",
+		"node_modules/pkg/index.js":  "",
+		".cache/tool/state.json":     "{}",
+		".cache/tool/node_modules/x": "",
+		"tracked.txt":                "",
+	}
+	for name, content := range files {
+		path := filepath.Join(srcDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := exec.Command("git", "init")
+	cmd.Dir = srcDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v\n%s", err, out)
+	}
+	return srcDir
+}
+
+func TestSyncAllUntrackedIgnored(t *testing.T) {
+	srcDir := setupIgnoredRepo(t)
+	dstDir := t.TempDir()
+
+	items := []config.SyncItem{{UntrackedIgnored: true, Mode: "copy", When: "always"}}
+	opts := Options{IgnoreDirs: []string{".git", "node_modules"}}
+	if _, err := SyncAll(srcDir, dstDir, items, opts); err != nil {
+		t.Fatalf("SyncAll failed: %v", err)
+	}
+
+	for _, synced := range []string{".env", "apps/web/.env.local", "config/dev.local.json", ".cache/tool/state.json"} {
+		if _, err := os.Stat(filepath.Join(dstDir, synced)); err != nil {
+			t.Errorf("expected %s to be synced: %v", synced, err)
+		}
+	}
+	for _, skipped := range []string{"node_modules", ".cache/tool/node_modules", "tracked.txt", ".gitignore"} {
+		if _, err := os.Stat(filepath.Join(dstDir, skipped)); !os.IsNotExist(err) {
+			t.Errorf("expected %s not to be synced", skipped)
+		}
+	}
+}
+
+func TestSyncAllUntrackedIgnoredFilters(t *testing.T) {
+	srcDir := setupIgnoredRepo(t)
+	dstDir := t.TempDir()
+
+	items := []config.SyncItem{{
+		UntrackedIgnored: true,
+		Include:          []string{".env*", "**/*.json"},
+		Exclude:          []string{"apps/**"},
+		Mode:             "copy",
+		When:             "always",
+	}}
+	results, err := SyncAll(srcDir, dstDir, items, Options{IgnoreDirs: []string{"node_modules"}})
+	if err != nil {
+		t.Fatalf("SyncAll failed: %v", err)
+	}
+
+	want := map[string]bool{".env": true, "config/dev.local.json": true, ".cache/tool/state.json": true}
+	if len(results) != len(want) {
+		t.Fatalf("expected %d results, got %v", len(want), results)
+	}
+	for _, r := range results {
+		if !want[filepath.ToSlash(r.Src)] {
+			t.Errorf("unexpected result %s", r.Src)
+		}
+	}
+}
+
+func TestSyncAllUntrackedIgnoredExplicitHeavyDir(t *testing.T) {
+	srcDir := setupIgnoredRepo(t)
+	dstDir := t.TempDir()
+
+	items := []config.SyncItem{{
+		UntrackedIgnored: true,
+		Include:          []string{"node_modules"},
+		Mode:             "copy",
+		When:             "always",
+	}}
+	if _, err := SyncAll(srcDir, dstDir, items, Options{IgnoreDirs: []string{"node_modules"}}); err != nil {
+		t.Fatalf("SyncAll failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dstDir, "node_modules/pkg/index.js")); err != nil {
+		t.Errorf("expected explicitly included node_modules to be synced: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, ".env")); !os.IsNotExist(err) {
+		t.Error("expected .env to be filtered out by include")
+	}
+}
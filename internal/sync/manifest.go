@@ -0,0 +1,122 @@
+package sync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Devdha/wm/internal/config"
+)
+
+// Manifest records the content of every file synced into a worktree, so a
+// later sync can tell local edits apart from stale copies
+type Manifest struct {
+	Files map[string]ManifestEntry `json:"files"` // Keyed by slash separated destination path
+}
+
+// ManifestEntry describes the last sync of one destination file
+type ManifestEntry struct {
+	Src      string    `json:"src"`
+	Mode     string    `json:"mode"`
+	Hash     string    `json:"hash,omitempty"`   // sha256 of the content at sync time
+	Target   string    `json:"target,omitempty"` // Where a symlink points
+	SyncedAt time.Time `json:"synced_at"`
+}
+
+// NewManifest returns an empty manifest
+func NewManifest() *Manifest {
+	return &Manifest{Files: make(map[string]ManifestEntry)}
+}
+
+// LoadManifest reads a manifest, returning an empty one if it does not exist
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewManifest(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync manifest: %w", err)
+	}
+
+	m := NewManifest()
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to parse sync manifest: %w", err)
+	}
+	if m.Files == nil {
+		m.Files = make(map[string]ManifestEntry)
+	}
+	return m, nil
+}
+
+// Save atomically writes the manifest to path
+func (m *Manifest) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal sync manifest: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write sync manifest: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write sync manifest: %w", err)
+	}
+	return nil
+}
+
+func (m *Manifest) lookup(dst string) (ManifestEntry, bool) {
+	if m == nil {
+		return ManifestEntry{}, false
+	}
+	entry, ok := m.Files[filepath.ToSlash(dst)]
+	return entry, ok
+}
+
+// record stores what is now at dstPath: the target of a symlink, or the
+// content hash of anything else
+func (m *Manifest) record(item config.SyncItem, dstPath string) error {
+	if m == nil {
+		return nil
+	}
+
+	entry := ManifestEntry{Src: filepath.ToSlash(item.Src), Mode: item.Mode, SyncedAt: time.Now()}
+	switch item.Mode {
+	case "symlink":
+		target, err := os.Readlink(dstPath)
+		if err != nil {
+			return err
+		}
+		entry.Target = target
+	default:
+		hash, err := hashFile(dstPath)
+		if err != nil {
+			return err
+		}
+		entry.Hash = hash
+	}
+	m.Files[filepath.ToSlash(item.Dst)] = entry
+	return nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,122 @@
+package sync
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/Devdha/wm/internal/config"
+)
+
+// Change is a file that syncing would create or modify
+type Change struct {
+	Src   string // Relative to the source directory
+	Dst   string // Relative to the destination directory
+	Added bool   // The destination does not exist yet
+	Diff  string // Unified diff from the destination to the source
+}
+
+// Reverse returns the items for syncing from a worktree back into the root.
+// Symlinked, hardlinked and template items are left out: the worktree either
+// shares the root's file or holds a copy rendered from it. Files are always
+// copied and replace local edits, since the caller confirms the changes.
+func Reverse(items []config.SyncItem) []config.SyncItem {
+	return backwards(items, func(item config.SyncItem) string { return item.Src })
+}
+
+// Peer returns the items for syncing from one worktree into another, where
+// every file keeps its worktree path. Otherwise it is like Reverse.
+func Peer(items []config.SyncItem) []config.SyncItem {
+	return backwards(items, func(item config.SyncItem) string { return item.Dst })
+}
+
+func backwards(items []config.SyncItem, dst func(config.SyncItem) string) []config.SyncItem {
+	var reversed []config.SyncItem
+	for _, item := range items {
+		switch item.Mode {
+		case "symlink", "hardlink", "template":
+			continue
+		}
+
+		rev := item
+		if !isNegation(item.Src) {
+			rev.Src, rev.Dst = item.Dst, dst(item)
+			rev.Mode = "copy"
+			rev.When = "always"
+			rev.OnConflict = ConflictOverwrite
+		}
+		reversed = append(reversed, rev)
+	}
+	return reversed
+}
+
+// Preview returns the files that syncing copied items would create or
+// modify in dstDir, with a diff for each. Symlink and template items are
+// not previewed.
+func Preview(srcDir, dstDir string, items []config.SyncItem, opts Options) ([]Change, error) {
+	expanded, err := Expand(srcDir, items, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for _, item := range expanded {
+		if item.Mode == "symlink" || item.Mode == "template" {
+			continue
+		}
+
+		srcRoot := filepath.Join(srcDir, item.Src)
+		err := filepath.WalkDir(srcRoot, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if path == srcRoot && os.IsNotExist(err) {
+					return nil // Reported as missing when syncing
+				}
+				return err
+			}
+
+			rel, _ := filepath.Rel(srcRoot, path)
+			if rel != "." && isExcluded(rel, item.Exclude) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+
+			change := Change{Src: filepath.Join(item.Src, rel), Dst: filepath.Join(item.Dst, rel)}
+			change, changed, err := previewFile(srcDir, dstDir, change, item.When)
+			if changed {
+				changes = append(changes, change)
+			}
+			return err
+		})
+		if err != nil {
+			return changes, err
+		}
+	}
+	return changes, nil
+}
+
+func previewFile(srcDir, dstDir string, change Change, when string) (Change, bool, error) {
+	src, err := os.ReadFile(filepath.Join(srcDir, change.Src))
+	if err != nil {
+		return change, false, err
+	}
+
+	dst, err := os.ReadFile(filepath.Join(dstDir, change.Dst))
+	switch {
+	case os.IsNotExist(err):
+		change.Added = true
+		change.Diff = UnifiedDiff(nil, src, "/dev/null", "b/"+filepath.ToSlash(change.Dst))
+		return change, true, nil
+	case err != nil:
+		return change, false, err
+	case when == "missing":
+		return change, false, nil
+	}
+
+	change.Diff = UnifiedDiff(dst, src, "a/"+filepath.ToSlash(change.Dst), "b/"+filepath.ToSlash(change.Dst))
+	return change, change.Diff != "", nil
+}
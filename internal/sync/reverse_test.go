@@ -0,0 +1,91 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Devdha/wm/internal/config"
+)
+
+func TestReverse(t *testing.T) {
+	items := []config.SyncItem{
+		{Src: ".env.example", Dst: ".env", Mode: "copy", When: "missing"},
+		{Src: "certs", Dst: "certs", Mode: "symlink", When: "always"},
+		{Src: ".env.tmpl", Dst: ".env.local", Mode: "template", When: "always"},
+		{Src: "models", Dst: "models", Mode: "reflink", When: "always"},
+		{Src: "!models/cache"},
+	}
+
+	got := Reverse(items)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 items, got %v", got)
+	}
+	if got[0].Src != ".env" || got[0].Dst != ".env.example" || got[0].When != "always" {
+		t.Errorf("unexpected reversed item %+v", got[0])
+	}
+	if got[1].Src != "models" || got[1].Mode != "copy" {
+		t.Errorf("unexpected reversed item %+v", got[1])
+	}
+	if got[2].Src != "!models/cache" {
+		t.Errorf("expected negation to be kept, got %+v", got[2])
+	}
+}
+
+func TestPeer(t *testing.T) {
+	items := []config.SyncItem{
+		{Src: ".env.example", Dst: ".env", Mode: "copy", When: "missing"},
+		{Src: "certs", Dst: "certs", Mode: "symlink", When: "always"},
+	}
+
+	got := Peer(items)
+	if len(got) != 1 || got[0].Src != ".env" || got[0].Dst != ".env" {
+		t.Errorf("expected .env to map onto itself, got %+v", got)
+	}
+}
+
+func TestPreview(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+
+	files := map[string]string{
+		filepath.Join(srcDir, ".env"):              "SECRET=new\n",
+		filepath.Join(dstDir, ".env"):              "SECRET=old\n",
+		filepath.Join(srcDir, "config", "app.yml"): "debug: true\n",
+		filepath.Join(srcDir, "config", "same"):    "same\n",
+		filepath.Join(dstDir, "config", "same"):    "same\n",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	items := []config.SyncItem{
+		{Src: ".env", Dst: ".env", Mode: "copy", When: "always"},
+		{Src: "config", Dst: "config", Mode: "copy", When: "always"},
+		{Src: "missing", Dst: "missing", Mode: "copy", When: "always"},
+	}
+	changes, err := Preview(srcDir, dstDir, items, Options{})
+	if err != nil {
+		t.Fatalf("Preview failed: %v", err)
+	}
+
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", changes)
+	}
+	if changes[0].Dst != ".env" || changes[0].Added || changes[0].Diff == "" {
+		t.Errorf("expected .env to be modified, got %+v", changes[0])
+	}
+	if changes[1].Dst != filepath.Join("config", "app.yml") || !changes[1].Added {
+		t.Errorf("expected config/app.yml to be added, got %+v", changes[1])
+	}
+
+	// Preview must not touch the destination
+	if data, _ := os.ReadFile(filepath.Join(dstDir, ".env")); string(data) != "SECRET=old\n" {
+		t.Errorf("preview modified the destination: %q", data)
+	}
+}
@@ -0,0 +1,125 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Devdha/wm/internal/config"
+)
+
+func TestSyncFileHardlink(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+
+	content := []byte("model weights")
+	if err := os.WriteFile(filepath.Join(srcDir, "model.bin"), content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	item := config.SyncItem{Src: "model.bin", Dst: "model.bin", Mode: "hardlink"}
+	result, err := SyncFile(srcDir, dstDir, item)
+	if err != nil {
+		t.Fatalf("SyncFile failed: %v", err)
+	}
+	if result.Status != StatusHardlinked || result.Shared != int64(len(content)) || result.Copied != 0 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	srcInfo, _ := os.Stat(filepath.Join(srcDir, "model.bin"))
+	dstInfo, _ := os.Stat(filepath.Join(dstDir, "model.bin"))
+	if !os.SameFile(srcInfo, dstInfo) {
+		t.Error("expected destination to be a hardlink to the source")
+	}
+
+	if result, _ := SyncFile(srcDir, dstDir, item); result.Status != StatusUnchanged {
+		t.Errorf("expected unchanged on second sync, got %s", result.Status)
+	}
+
+	// Switching to copy must break the link rather than keep sharing it
+	item.Mode = "copy"
+	result, err = SyncFile(srcDir, dstDir, item)
+	if err != nil {
+		t.Fatalf("SyncFile failed: %v", err)
+	}
+	if result.Status != StatusCopied || result.Copied != int64(len(content)) {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	dstInfo, _ = os.Stat(filepath.Join(dstDir, "model.bin"))
+	if os.SameFile(srcInfo, dstInfo) {
+		t.Error("expected copy to replace the hardlink")
+	}
+}
+
+func TestSyncFileReflink(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+
+	content := []byte("fixture database")
+	if err := os.WriteFile(filepath.Join(srcDir, "fixture.db"), content, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reflinks depend on the filesystem; either way the content must match
+	item := config.SyncItem{Src: "fixture.db", Dst: "fixture.db", Mode: "reflink"}
+	result, err := SyncFile(srcDir, dstDir, item)
+	if err != nil {
+		t.Fatalf("SyncFile failed: %v", err)
+	}
+	switch result.Status {
+	case StatusCloned:
+		if result.Shared != int64(len(content)) {
+			t.Errorf("expected shared bytes, got %+v", result)
+		}
+	case StatusCopied:
+		if result.Copied != int64(len(content)) {
+			t.Errorf("expected copied bytes, got %+v", result)
+		}
+	default:
+		t.Fatalf("unexpected status %s", result.Status)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dstDir, "fixture.db"))
+	if err != nil || string(data) != string(content) {
+		t.Errorf("expected cloned content, got %q (%v)", data, err)
+	}
+	if info, _ := os.Stat(filepath.Join(dstDir, "fixture.db")); info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %o", info.Mode().Perm())
+	}
+}
+
+func TestShareFileFallback(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(srcDir, "a"), []byte("abc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	src, dst := filepath.Join(srcDir, "a"), filepath.Join(dstDir, "a")
+	unsupported := func(src, dst string) error { return os.ErrInvalid }
+
+	item := config.SyncItem{Src: "a", Mode: "reflink"}
+	result, err := shareFile(src, dst, item, Result{}, StatusCloned, unsupported)
+	if err != nil || result.Status != StatusCopied || result.Copied != 3 {
+		t.Fatalf("expected fallback copy, got %+v (%v)", result, err)
+	}
+
+	os.Remove(dst)
+	item.Fallback = "error"
+	if _, err := shareFile(src, dst, item, Result{}, StatusCloned, unsupported); err == nil {
+		t.Error("expected an error with fallback: error")
+	}
+}
+
+func TestSummary(t *testing.T) {
+	results := []Result{
+		{Status: StatusCopied, Copied: 1536},
+		{Status: StatusHardlinked, Shared: 3 << 30},
+		{Status: StatusUnchanged},
+	}
+
+	want := "2 file(s), 1.5 KB copied, 3.0 GB shared"
+	if got := Summarize(results).String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,230 @@
+package sync
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Devdha/wm/internal/config"
+)
+
+// State describes how a synced path in a worktree compares to its source
+type State string
+
+const (
+	StateInSync   State = "in-sync"   // Matches what syncing would write
+	StateStale    State = "stale"     // The source changed since the last sync
+	StateModified State = "modified"  // Edited in the worktree since the last sync
+	StateMissing  State = "missing"   // Not present in the worktree
+	StateDangling State = "dangling"  // A symlink whose target does not exist
+	StateNoSource State = "no-source" // The source does not exist
+)
+
+// FileStatus is the state of one synced path
+type FileStatus struct {
+	Src      string // Relative to the source directory
+	Dst      string // Relative to the destination directory
+	Mode     string
+	State    State
+	SyncedAt time.Time // When wm last wrote or checked the path; zero if unknown
+}
+
+// Check compares what items would sync from srcDir with what is in dstDir,
+// using opts.Manifest to tell local edits apart from stale copies. Files
+// without a manifest record that differ from their source count as stale.
+func Check(srcDir, dstDir string, items []config.SyncItem, opts Options) ([]FileStatus, error) {
+	var statuses []FileStatus
+	err := checkItems(srcDir, dstDir, items, opts, func(_ config.SyncItem, status FileStatus) error {
+		statuses = append(statuses, status)
+		return nil
+	})
+	return statuses, err
+}
+
+// Repair relinks mode: symlink items whose link in dstDir dangles, e.g.
+// after the repository or worktrees were moved, and returns what it did.
+// Links whose source is gone are reported as skipped-missing.
+func Repair(srcDir, dstDir string, items []config.SyncItem, opts Options) ([]Result, error) {
+	var results []Result
+	err := checkItems(srcDir, dstDir, items, opts, func(item config.SyncItem, status FileStatus) error {
+		if item.Mode != "symlink" || status.State != StateDangling {
+			return nil
+		}
+		item.When = "always"
+		result, err := syncFile(srcDir, dstDir, item, opts)
+		results = append(results, result)
+		return err
+	})
+	return results, err
+}
+
+// checkItems calls visit with the status of every path items sync, along
+// with the item for that path
+func checkItems(srcDir, dstDir string, items []config.SyncItem, opts Options, visit func(config.SyncItem, FileStatus) error) error {
+	expanded, err := Expand(srcDir, items, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range expanded {
+		srcRoot := filepath.Join(srcDir, item.Src)
+		info, err := os.Stat(srcRoot)
+		if err != nil || !info.IsDir() || item.Mode == "template" || (item.Mode == "symlink" && len(item.Exclude) == 0) {
+			status, err := checkFile(srcDir, dstDir, item, opts)
+			if err != nil {
+				return err
+			}
+			if err := visit(item, status); err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = filepath.WalkDir(srcRoot, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			rel, _ := filepath.Rel(srcRoot, path)
+			if rel != "." && isExcluded(rel, item.Exclude) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			sub := item
+			sub.Src = filepath.Join(item.Src, rel)
+			sub.Dst = filepath.Join(item.Dst, rel)
+			switch {
+			case d.Type()&fs.ModeSymlink != 0:
+				sub.Mode = "copy" // Recreated with the same target, not linked to the source
+				return visit(sub, checkCopiedLink(srcDir, dstDir, sub))
+			case d.Type().IsRegular():
+				status, err := checkFile(srcDir, dstDir, sub, opts)
+				if err != nil {
+					return err
+				}
+				return visit(sub, status)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkFile(srcDir, dstDir string, item config.SyncItem, opts Options) (FileStatus, error) {
+	srcPath := filepath.Join(srcDir, item.Src)
+	dstPath := filepath.Join(dstDir, item.Dst)
+	status := FileStatus{Src: item.Src, Dst: item.Dst, Mode: item.Mode}
+	entry, recorded := opts.Manifest.lookup(item.Dst)
+	status.SyncedAt = entry.SyncedAt
+
+	dstInfo, err := os.Lstat(dstPath)
+	switch {
+	case err != nil:
+		status.State = StateMissing
+		if _, err := os.Stat(srcPath); err != nil {
+			status.State = StateNoSource
+		}
+		return status, nil
+	case dstInfo.Mode()&os.ModeSymlink != 0:
+		if _, err := os.Stat(dstPath); err != nil {
+			status.State = StateDangling
+			return status, nil
+		}
+	}
+
+	srcInfo, err := os.Stat(srcPath)
+	if err != nil {
+		status.State = StateNoSource
+		return status, nil
+	}
+	if item.When == "missing" {
+		status.State = StateInSync // Present, and sync leaves it alone
+		return status, nil
+	}
+
+	switch item.Mode {
+	case "symlink":
+		ok, err := isUpToDate(srcPath, dstPath, item, opts)
+		dstStat, statErr := os.Stat(dstPath)
+		switch {
+		case err != nil:
+			return status, err
+		case ok:
+			status.State = StateInSync
+		case statErr == nil && dstInfo.Mode()&os.ModeSymlink != 0 && os.SameFile(srcInfo, dstStat):
+			status.State = StateStale // Points at the source, but not as configured
+		default:
+			status.State = StateModified // Replaced by a file or a different link
+		}
+		return status, nil
+	case "hardlink":
+		if dstStat, err := os.Stat(dstPath); err == nil && os.SameFile(srcInfo, dstStat) {
+			status.State = StateInSync
+			return status, nil
+		}
+	}
+
+	var want []byte
+	if item.Mode == "template" {
+		if opts.Template == nil {
+			return status, fmt.Errorf("no template variables for %s", item.Src)
+		}
+		want, err = renderTemplate(srcPath, opts.Template)
+	} else {
+		want, err = os.ReadFile(srcPath)
+	}
+	if err != nil {
+		return status, err
+	}
+	have, err := os.ReadFile(dstPath)
+	if err != nil {
+		return status, fmt.Errorf("failed to read %s: %w", item.Dst, err)
+	}
+
+	hash, _ := hashFile(dstPath)
+	switch {
+	case bytes.Equal(have, want):
+		status.State = StateInSync
+	case recorded && entry.Mode != "symlink" && hash != entry.Hash:
+		status.State = StateModified
+	default:
+		status.State = StateStale
+	}
+	return status, nil
+}
+
+// checkCopiedLink compares a symlink found inside a synced directory, which
+// sync recreates with the same target
+func checkCopiedLink(srcDir, dstDir string, item config.SyncItem) FileStatus {
+	status := FileStatus{Src: item.Src, Dst: item.Dst, Mode: item.Mode}
+	dstPath := filepath.Join(dstDir, item.Dst)
+
+	have, err := os.Readlink(dstPath)
+	if err != nil {
+		status.State = StateMissing
+		if _, err := os.Lstat(dstPath); err == nil {
+			status.State = StateModified
+		}
+		return status
+	}
+	want, _ := os.Readlink(filepath.Join(srcDir, item.Src))
+
+	switch _, err := os.Stat(dstPath); {
+	case have != want:
+		status.State = StateModified
+	case err != nil:
+		status.State = StateDangling
+	default:
+		status.State = StateInSync
+	}
+	return status
+}
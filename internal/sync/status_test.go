@@ -0,0 +1,164 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Devdha/wm/internal/config"
+)
+
+func TestCheck(t *testing.T) {
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	for name, content := range map[string]string{
+		".env":          "A=1\n",
+		"local.env":     "B=1\n",
+		"conf/a.json":   "{}\n",
+		"conf/b.json":   "[]\n",
+		"shared.txt":    "shared\n",
+		"untracked.txt": "u\n",
+	} {
+		path := filepath.Join(srcDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	items := []config.SyncItem{
+		{Src: ".env", Dst: ".env", Mode: "copy", When: "always"},
+		{Src: "local.env", Dst: "local.env", Mode: "copy", When: "always"},
+		{Src: "conf", Dst: "conf", Mode: "copy", When: "always"},
+		{Src: "shared.txt", Dst: "shared.txt", Mode: "symlink", When: "always"},
+		{Src: "gone.txt", Dst: "gone.txt", Mode: "copy", When: "always"},
+	}
+	manifest := NewManifest()
+	if _, err := SyncAll(srcDir, dstDir, items, Options{Manifest: manifest}); err != nil {
+		t.Fatalf("SyncAll failed: %v", err)
+	}
+	if entry := manifest.Files["shared.txt"]; entry.Mode != "symlink" || entry.Target == "" {
+		t.Errorf("expected symlink to be recorded with its target, got %+v", entry)
+	}
+
+	// Not synced by wm, so differences cannot be told apart from staleness
+	items = append(items, config.SyncItem{Src: "untracked.txt", Dst: "untracked.txt", Mode: "copy", When: "always"})
+	if err := os.WriteFile(filepath.Join(dstDir, "untracked.txt"), []byte("mine\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(srcDir, ".env"), []byte("A=2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dstDir, "local.env"), []byte("B=2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dstDir, "conf", "b.json")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(srcDir, "shared.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := Check(srcDir, dstDir, items, Options{Manifest: manifest})
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	want := map[string]State{
+		".env":                          StateStale,
+		"local.env":                     StateModified,
+		filepath.Join("conf", "a.json"): StateInSync,
+		filepath.Join("conf", "b.json"): StateMissing,
+		"shared.txt":                    StateDangling,
+		"gone.txt":                      StateNoSource,
+		"untracked.txt":                 StateStale,
+	}
+	if len(statuses) != len(want) {
+		t.Fatalf("expected %d statuses, got %+v", len(want), statuses)
+	}
+	for _, s := range statuses {
+		if s.State != want[s.Dst] {
+			t.Errorf("%s: expected %s, got %s", s.Dst, want[s.Dst], s.State)
+		}
+		if s.Dst == ".env" && s.SyncedAt.IsZero() {
+			t.Errorf("expected sync time for .env")
+		}
+	}
+}
+
+func TestCheckHardlink(t *testing.T) {
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(srcDir, "data.bin"), []byte("v1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	items := []config.SyncItem{{Src: "data.bin", Dst: "data.bin", Mode: "hardlink", When: "always"}}
+	manifest := NewManifest()
+	if _, err := SyncAll(srcDir, dstDir, items, Options{Manifest: manifest}); err != nil {
+		t.Fatalf("SyncAll failed: %v", err)
+	}
+
+	check := func(want State) {
+		t.Helper()
+		statuses, err := Check(srcDir, dstDir, items, Options{Manifest: manifest})
+		if err != nil {
+			t.Fatalf("Check failed: %v", err)
+		}
+		if len(statuses) != 1 || statuses[0].State != want {
+			t.Errorf("expected %s, got %+v", want, statuses)
+		}
+	}
+	check(StateInSync)
+
+	// Replacing the source, as editors do on save, breaks the link
+	tmp := filepath.Join(srcDir, "data.tmp")
+	if err := os.WriteFile(tmp, []byte("v2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, filepath.Join(srcDir, "data.bin")); err != nil {
+		t.Fatal(err)
+	}
+	check(StateStale)
+}
+
+func TestRepair(t *testing.T) {
+	root := t.TempDir()
+	srcDir := filepath.Join(root, "repo")
+	dstDir := filepath.Join(root, "wt")
+	if err := os.MkdirAll(srcDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{".env", "secrets.json"} {
+		if err := os.WriteFile(filepath.Join(srcDir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	items := []config.SyncItem{
+		{Src: ".env", Dst: ".env", Mode: "symlink", When: "always"},
+		{Src: "secrets.json", Dst: "secrets.json", Mode: "symlink", When: "always"},
+	}
+	if _, err := SyncAll(srcDir, dstDir, items, Options{}); err != nil {
+		t.Fatalf("SyncAll failed: %v", err)
+	}
+
+	// Moving the repository leaves the absolute links dangling
+	movedDir := filepath.Join(root, "moved")
+	if err := os.Rename(srcDir, movedDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(movedDir, "secrets.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := Repair(movedDir, dstDir, items, Options{})
+	if err != nil {
+		t.Fatalf("Repair failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Status != StatusLinked || results[1].Status != StatusSkippedMissing {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if content, err := os.ReadFile(filepath.Join(dstDir, ".env")); err != nil || string(content) != ".env" {
+		t.Errorf("expected .env to be relinked, got %q, %v", content, err)
+	}
+}
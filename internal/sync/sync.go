@@ -1,35 +1,149 @@
 package sync
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/Devdha/wm/internal/config"
 )
 
+// Status describes what SyncFile did with a path
+type Status string
+
+const (
+	StatusCopied         Status = "copied"
+	StatusLinked         Status = "linked"
+	StatusCloned         Status = "cloned"          // Reflinked, sharing blocks with the source
+	StatusHardlinked     Status = "hardlinked"      // Same inode as the source
+	StatusRendered       Status = "rendered"        // Written from a template
+	StatusUnchanged      Status = "unchanged"       // Destination already matches the source
+	StatusSkippedMissing Status = "skipped-missing" // Source does not exist
+	StatusSkippedExists  Status = "skipped-exists"  // when: missing and destination exists
+	StatusConflict       Status = "conflict"        // Destination has local changes, which were kept
+)
+
+// Result is the outcome of syncing one path
+type Result struct {
+	Src    string // Relative to the source directory
+	Dst    string // Relative to the destination directory
+	Status Status
+	Copied int64  // Bytes written to the destination
+	Shared int64  // Bytes shared with the source through a reflink or hardlink
+	Backup string // Where local changes were moved before overwriting them
+}
+
+// Changed reports whether the destination was written
+func (r Result) Changed() bool {
+	switch r.Status {
+	case StatusCopied, StatusLinked, StatusCloned, StatusHardlinked, StatusRendered:
+		return true
+	}
+	return false
+}
+
+// Summary totals the results of a sync
+type Summary struct {
+	Files  int   // Paths written to the destination
+	Copied int64 // Bytes copied
+	Shared int64 // Bytes shared with the source through reflinks or hardlinks
+}
+
+// Summarize adds up results
+func Summarize(results []Result) Summary {
+	var s Summary
+	for _, r := range results {
+		if r.Changed() {
+			s.Files++
+		}
+		s.Copied += r.Copied
+		s.Shared += r.Shared
+	}
+	return s
+}
+
+// String describes the summary, e.g. "3 file(s), 1.2 MB copied, 4.0 GB shared"
+func (s Summary) String() string {
+	text := fmt.Sprintf("%d file(s), %s copied", s.Files, formatBytes(s.Copied))
+	if s.Shared > 0 {
+		text += fmt.Sprintf(", %s shared", formatBytes(s.Shared))
+	}
+	return text
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
 // SyncFile syncs a single file from srcDir to dstDir based on SyncItem config
-func SyncFile(srcDir, dstDir string, item config.SyncItem) error {
+func SyncFile(srcDir, dstDir string, item config.SyncItem) (Result, error) {
+	return syncFile(srcDir, dstDir, item, Options{})
+}
+
+func syncFile(srcDir, dstDir string, item config.SyncItem, opts Options) (Result, error) {
 	srcPath := filepath.Join(srcDir, item.Src)
 	dstPath := filepath.Join(dstDir, item.Dst)
+	result := Result{Src: item.Src, Dst: item.Dst}
 
 	// Check if source exists
 	if _, err := os.Stat(srcPath); os.IsNotExist(err) {
-		return nil // Skip if source doesn't exist
+		result.Status = StatusSkippedMissing
+		return result, nil // Skip if source doesn't exist
 	}
 
 	// Check "when" condition
 	if item.When == "missing" {
 		if _, err := os.Stat(dstPath); err == nil {
-			return nil // Skip if dest already exists
+			result.Status = StatusSkippedExists
+			return result, nil // Skip if dest already exists
 		}
 	}
 
+	unchanged, err := isUpToDate(srcPath, dstPath, item, opts)
+	if err != nil {
+		return result, err
+	}
+	if unchanged {
+		result.Status = StatusUnchanged
+		return result, recordSync(item, dstPath, opts)
+	}
+
+	incoming := func() ([]byte, error) { return os.ReadFile(srcPath) }
+	if ok, err := resolveConflict(dstPath, item, incoming, opts, &result); !ok {
+		return result, err
+	}
+
+	result, err = writeFile(srcPath, dstPath, item, opts, result)
+	if err != nil || !result.Changed() {
+		return result, err
+	}
+	return result, recordSync(item, dstPath, opts)
+}
+
+// recordSync notes what is now at dstPath in the manifest
+func recordSync(item config.SyncItem, dstPath string, opts Options) error {
+	return opts.Manifest.record(item, dstPath)
+}
+
+// writeFile replaces dstPath with src according to the item's mode
+func writeFile(srcPath, dstPath string, item config.SyncItem, opts Options, result Result) (Result, error) {
 	// Ensure destination directory exists
 	dstParent := filepath.Dir(dstPath)
 	if err := os.MkdirAll(dstParent, 0755); err != nil {
-		return fmt.Errorf("failed to create dest directory: %w", err)
+		return result, fmt.Errorf("failed to create dest directory: %w", err)
 	}
 
 	// Remove existing destination if it exists
@@ -37,9 +151,120 @@ func SyncFile(srcDir, dstDir string, item config.SyncItem) error {
 
 	switch item.Mode {
 	case "symlink":
-		return createSymlink(srcPath, dstPath)
+		result.Status = StatusLinked
+		return result, createSymlink(srcPath, dstPath, relativeLink(item, opts))
+	case "reflink":
+		return shareFile(srcPath, dstPath, item, result, StatusCloned, cloneFile)
+	case "hardlink":
+		return shareFile(srcPath, dstPath, item, result, StatusHardlinked, hardlinkFile)
 	default: // "copy"
-		return copyFile(srcPath, dstPath)
+		return copyResult(srcPath, dstPath, result)
+	}
+}
+
+// shareFile creates dst with link, which shares the data of src instead of
+// copying it. If that is not possible, e.g. across filesystems, the file is
+// copied unless the item's fallback is "error".
+func shareFile(src, dst string, item config.SyncItem, result Result, status Status, link func(src, dst string) error) (Result, error) {
+	err := link(src, dst)
+	if err == nil {
+		result.Status = status
+		if info, err := os.Stat(dst); err == nil {
+			result.Shared = info.Size()
+		}
+		return result, nil
+	}
+	if item.Fallback == "error" {
+		return result, fmt.Errorf("failed to %s %s: %w", item.Mode, item.Src, err)
+	}
+	return copyResult(src, dst, result)
+}
+
+func copyResult(src, dst string, result Result) (Result, error) {
+	result.Status = StatusCopied
+	if err := copyFile(src, dst); err != nil {
+		return result, err
+	}
+	if info, err := os.Stat(dst); err == nil {
+		result.Copied = info.Size()
+	}
+	return result, nil
+}
+
+func hardlinkFile(src, dst string) error {
+	// Link the file itself rather than a symlink pointing at it
+	target, err := filepath.EvalSymlinks(src)
+	if err != nil {
+		return err
+	}
+	return os.Link(target, dst)
+}
+
+// isUpToDate reports whether dst already is what syncing src would produce
+func isUpToDate(src, dst string, item config.SyncItem, opts Options) (bool, error) {
+	mode := item.Mode
+	dstInfo, err := os.Lstat(dst)
+	if err != nil {
+		return false, nil
+	}
+
+	if mode == "symlink" {
+		if dstInfo.Mode()&os.ModeSymlink == 0 {
+			return false, nil
+		}
+		want, err := symlinkTarget(src, dst, relativeLink(item, opts))
+		if err != nil {
+			return false, err
+		}
+		target, err := os.Readlink(dst)
+		return err == nil && target == want, nil
+	}
+
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat source: %w", err)
+	}
+	// A hardlink is up to date exactly when it is the source; any other mode
+	// must replace one so edits in the worktree stay out of the source
+	if sameFile := os.SameFile(srcInfo, dstInfo); mode == "hardlink" || sameFile {
+		return mode == "hardlink" && sameFile, nil
+	}
+	if !dstInfo.Mode().IsRegular() || !srcInfo.Mode().IsRegular() ||
+		dstInfo.Size() != srcInfo.Size() || dstInfo.Mode().Perm() != srcInfo.Mode().Perm() {
+		return false, nil
+	}
+	return sameContent(src, dst)
+}
+
+func sameContent(a, b string) (bool, error) {
+	fa, err := os.Open(a)
+	if err != nil {
+		return false, fmt.Errorf("failed to open source: %w", err)
+	}
+	defer fa.Close()
+
+	fb, err := os.Open(b)
+	if err != nil {
+		return false, nil
+	}
+	defer fb.Close()
+
+	bufA, bufB := make([]byte, 64*1024), make([]byte, 64*1024)
+	for {
+		na, errA := io.ReadFull(fa, bufA)
+		nb, errB := io.ReadFull(fb, bufB)
+		if !bytes.Equal(bufA[:na], bufB[:nb]) {
+			return false, nil
+		}
+		if errA == io.EOF || errA == io.ErrUnexpectedEOF {
+			return errB == io.EOF || errB == io.ErrUnexpectedEOF, nil
+		}
+		if errA != nil {
+			return false, fmt.Errorf("failed to read source: %w", errA)
+		}
+		if errB != nil {
+			return false, nil
+		}
 	}
 }
 
@@ -68,50 +293,251 @@ func copyFile(src, dst string) error {
 	return nil
 }
 
-func createSymlink(src, dst string) error {
-	// Use absolute path for symlink target
-	absSrc, err := filepath.Abs(src)
+func createSymlink(src, dst string, relative bool) error {
+	target, err := symlinkTarget(src, dst, relative)
 	if err != nil {
-		return fmt.Errorf("failed to get absolute path: %w", err)
+		return err
 	}
 
-	if err := os.Symlink(absSrc, dst); err != nil {
+	if err := os.Symlink(target, dst); err != nil {
 		return fmt.Errorf("failed to create symlink: %w", err)
 	}
 
 	return nil
 }
 
-// SyncAll syncs all files from config
-func SyncAll(srcDir, dstDir string, items []config.SyncItem) error {
-	for _, item := range items {
-		// Handle glob patterns
-		matches, err := filepath.Glob(filepath.Join(srcDir, item.Src))
+// symlinkTarget returns what a link at dst pointing to src contains: the
+// absolute path of src, or its path relative to the link's directory
+func symlinkTarget(src, dst string, relative bool) (string, error) {
+	absSrc, err := filepath.Abs(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	if !relative {
+		return absSrc, nil
+	}
+
+	absDst, err := filepath.Abs(dst)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	target, err := filepath.Rel(filepath.Dir(absDst), absSrc)
+	if err != nil {
+		return "", fmt.Errorf("failed to make symlink relative: %w", err)
+	}
+	return target, nil
+}
+
+// relativeLink reports whether a mode: symlink item gets a relative target
+func relativeLink(item config.SyncItem, opts Options) bool {
+	if item.Relative != nil {
+		return *item.Relative
+	}
+	return opts.RelativeLinks
+}
+
+// Options holds settings that apply to every sync item
+type Options struct {
+	IgnoreDirs    []string      // Directory names "**" patterns never descend into
+	RelativeLinks bool          // Default for items that do not set relative
+	Template      *TemplateData // Variables for mode: template items
+	Manifest      *Manifest     // Hashes of earlier syncs; updated as files are written
+	// Resolve picks a policy for conflicts of on_conflict: prompt items.
+	// Without it such conflicts are skipped.
+	Resolve func(Conflict) string
+}
+
+// SyncAll syncs all files from config and returns one Result per path.
+// Sources may use "**" to match any number of directories, and a source
+// starting with "!" excludes matching paths from the items before it.
+// Items with untracked_ignored sync the files git ignores in srcDir.
+func SyncAll(srcDir, dstDir string, items []config.SyncItem, opts Options) ([]Result, error) {
+	expanded, err := Expand(srcDir, items, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	for _, item := range expanded {
+		pathResults, err := syncMatch(srcDir, dstDir, item, opts)
+		results = append(results, pathResults...)
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// Expand resolves globs, negations and untracked_ignored in items to one
+// item per source file or directory in srcDir. Sources that do not exist
+// are kept as they are, so syncing reports them as missing.
+func Expand(srcDir string, items []config.SyncItem, opts Options) ([]config.SyncItem, error) {
+	var expanded []config.SyncItem
+	for i, item := range items {
+		if isNegation(item.Src) {
+			continue
+		}
+		negations := laterNegations(items[i+1:])
+
+		if item.UntrackedIgnored {
+			ignored, err := expandIgnored(srcDir, item, negations, opts)
+			if err != nil {
+				return nil, err
+			}
+			expanded = append(expanded, ignored...)
+			continue
+		}
+
+		matches, err := expandPattern(srcDir, item.Src, opts.IgnoreDirs)
 		if err != nil {
-			return fmt.Errorf("invalid glob pattern %s: %w", item.Src, err)
+			return nil, fmt.Errorf("invalid glob pattern %s: %w", item.Src, err)
 		}
 
 		if len(matches) == 0 {
 			// No glob match, try as literal path
-			if err := SyncFile(srcDir, dstDir, item); err != nil {
-				return err
+			if !negatedBy(item.Src, negations) {
+				expanded = append(expanded, item)
 			}
 			continue
 		}
 
-		// Process each glob match
 		for _, match := range matches {
 			relPath, _ := filepath.Rel(srcDir, match)
+			if negatedBy(relPath, negations) {
+				continue
+			}
 			itemCopy := item
 			itemCopy.Src = relPath
 			if item.Dst == item.Src || item.Dst == "" {
 				itemCopy.Dst = relPath
 			}
-			if err := SyncFile(srcDir, dstDir, itemCopy); err != nil {
-				return err
+			expanded = append(expanded, itemCopy)
+		}
+	}
+	return expanded, nil
+}
+
+// syncMatch syncs one expanded item, rendering templates with opts
+func syncMatch(srcDir, dstDir string, item config.SyncItem, opts Options) ([]Result, error) {
+	if item.Mode == "template" {
+		result, err := renderFile(srcDir, dstDir, item, opts)
+		return []Result{result}, err
+	}
+	return syncPath(srcDir, dstDir, item, opts)
+}
+
+// laterNegations returns the "!pattern" sources among items, which apply
+// to every item listed before them
+func laterNegations(items []config.SyncItem) []string {
+	var negations []string
+	for _, item := range items {
+		if isNegation(item.Src) {
+			negations = append(negations, item.Src)
+		}
+	}
+	return negations
+}
+
+// SourceDirs returns the directories in srcDir that hold the sources of
+// items, so that watching them catches edits, creations and atomic renames
+// of synced files
+func SourceDirs(srcDir string, items []config.SyncItem, opts Options) []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	add := func(dir string) {
+		if seen[dir] {
+			return
+		}
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+
+	for _, item := range items {
+		if isNegation(item.Src) {
+			continue
+		}
+		if item.UntrackedIgnored {
+			add(srcDir)
+			sources, _ := ignoredSources(srcDir, item, opts.IgnoreDirs)
+			heavy := heavyDirs(item.Include, opts.IgnoreDirs)
+			for _, rel := range sources {
+				source := filepath.Join(srcDir, rel)
+				add(filepath.Dir(source))
+				filepath.WalkDir(source, func(path string, d fs.DirEntry, err error) error {
+					if err != nil || !d.IsDir() {
+						return nil
+					}
+					if heavy[d.Name()] {
+						return filepath.SkipDir
+					}
+					add(path)
+					return nil
+				})
+			}
+			continue
+		}
+		matches, _ := expandPattern(srcDir, item.Src, opts.IgnoreDirs)
+		for _, match := range matches {
+			add(filepath.Dir(match))
+			// Copied directories are synced file by file, so watch inside them
+			if item.Mode != "symlink" || len(item.Exclude) > 0 {
+				filepath.WalkDir(match, func(path string, d fs.DirEntry, err error) error {
+					if err != nil || !d.IsDir() {
+						return nil
+					}
+					if rel, _ := filepath.Rel(match, path); rel != "." && isExcluded(rel, item.Exclude) {
+						return filepath.SkipDir
+					}
+					add(path)
+					return nil
+				})
 			}
 		}
+		// Also watch where a literal source would appear once created, or the
+		// deepest directory above the first wildcard for globs
+		add(filepath.Join(srcDir, staticPrefix(filepath.Dir(item.Src))))
 	}
+	return dirs
+}
 
-	return nil
+// Affected reports whether any of paths, absolute paths in srcDir, is a
+// source of items or lies inside a synced directory without being excluded.
+// Globs and untracked_ignored are expanded now, so newly created sources
+// count as well.
+func Affected(srcDir string, items []config.SyncItem, opts Options, paths []string) (bool, error) {
+	expanded, err := Expand(srcDir, items, opts)
+	if err != nil {
+		return false, err
+	}
+
+	for _, item := range expanded {
+		root := filepath.Join(srcDir, item.Src)
+		for _, path := range paths {
+			if path == root {
+				return true, nil
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				continue
+			}
+			if !isExcluded(rel, item.Exclude) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// staticPrefix returns the leading path components of pattern that contain
+// no glob metacharacters
+func staticPrefix(pattern string) string {
+	parts := strings.Split(filepath.ToSlash(pattern), "/")
+	for i, part := range parts {
+		if hasGlobMeta(part) {
+			return filepath.FromSlash(strings.Join(parts[:i], "/"))
+		}
+	}
+	return pattern
 }
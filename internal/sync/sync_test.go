@@ -25,7 +25,7 @@ func TestSyncCopy(t *testing.T) {
 		When: "always",
 	}
 
-	if err := SyncFile(srcDir, dstDir, item); err != nil {
+	if _, err := SyncFile(srcDir, dstDir, item); err != nil {
 		t.Fatalf("SyncFile failed: %v", err)
 	}
 
@@ -58,7 +58,7 @@ func TestSyncSymlink(t *testing.T) {
 		When: "always",
 	}
 
-	if err := SyncFile(srcDir, dstDir, item); err != nil {
+	if _, err := SyncFile(srcDir, dstDir, item); err != nil {
 		t.Fatalf("SyncFile failed: %v", err)
 	}
 
@@ -74,6 +74,58 @@ func TestSyncSymlink(t *testing.T) {
 	}
 }
 
+func TestSyncRelativeSymlink(t *testing.T) {
+	root := t.TempDir()
+	srcDir := filepath.Join(root, "repo")
+	dstDir := filepath.Join(root, "wm_repo", "feature")
+	if err := os.MkdirAll(filepath.Join(srcDir, "config"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "config", "dev.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	relative := true
+	items := []config.SyncItem{{Src: "config/dev.json", Dst: "config/dev.json", Mode: "symlink", When: "always", Relative: &relative}}
+	if _, err := SyncAll(srcDir, dstDir, items, Options{}); err != nil {
+		t.Fatalf("SyncAll failed: %v", err)
+	}
+
+	link := filepath.Join(dstDir, "config", "dev.json")
+	target, err := os.Readlink(link)
+	if err != nil {
+		t.Fatalf("expected symlink: %v", err)
+	}
+	want := filepath.Join("..", "..", "..", "repo", "config", "dev.json")
+	if target != want {
+		t.Errorf("expected target %s, got %s", want, target)
+	}
+
+	// Moving both trees together keeps the link working
+	moved := t.TempDir()
+	if err := os.Rename(root, filepath.Join(moved, "tree")); err != nil {
+		t.Fatal(err)
+	}
+	if content, err := os.ReadFile(filepath.Join(moved, "tree", "wm_repo", "feature", "config", "dev.json")); err != nil || string(content) != "{}" {
+		t.Errorf("link broken after move: %q, %v", content, err)
+	}
+
+	// Switching to the default turns the link absolute again
+	srcDir = filepath.Join(moved, "tree", "repo")
+	dstDir = filepath.Join(moved, "tree", "wm_repo", "feature")
+	items[0].Relative = nil
+	results, err := SyncAll(srcDir, dstDir, items, Options{})
+	if err != nil {
+		t.Fatalf("SyncAll failed: %v", err)
+	}
+	if results[0].Status != StatusLinked {
+		t.Errorf("expected link to be replaced, got %s", results[0].Status)
+	}
+	if target, _ := os.Readlink(filepath.Join(dstDir, "config", "dev.json")); !filepath.IsAbs(target) {
+		t.Errorf("expected absolute target, got %s", target)
+	}
+}
+
 func TestSyncWhenMissing(t *testing.T) {
 	srcDir := t.TempDir()
 	dstDir := t.TempDir()
@@ -95,7 +147,7 @@ func TestSyncWhenMissing(t *testing.T) {
 		When: "missing",
 	}
 
-	if err := SyncFile(srcDir, dstDir, item); err != nil {
+	if _, err := SyncFile(srcDir, dstDir, item); err != nil {
 		t.Fatalf("SyncFile failed: %v", err)
 	}
 
@@ -123,7 +175,7 @@ func TestSyncWhenMissingCopiesIfNotExists(t *testing.T) {
 		When: "missing",
 	}
 
-	if err := SyncFile(srcDir, dstDir, item); err != nil {
+	if _, err := SyncFile(srcDir, dstDir, item); err != nil {
 		t.Fatalf("SyncFile failed: %v", err)
 	}
 
@@ -151,7 +203,7 @@ func TestSyncSourceNotExists(t *testing.T) {
 	}
 
 	// Should not error when source doesn't exist
-	if err := SyncFile(srcDir, dstDir, item); err != nil {
+	if _, err := SyncFile(srcDir, dstDir, item); err != nil {
 		t.Fatalf("SyncFile failed: %v", err)
 	}
 
@@ -183,7 +235,7 @@ func TestSyncAllWithGlob(t *testing.T) {
 		},
 	}
 
-	if err := SyncAll(srcDir, dstDir, items); err != nil {
+	if _, err := SyncAll(srcDir, dstDir, items, Options{}); err != nil {
 		t.Fatalf("SyncAll failed: %v", err)
 	}
 
@@ -222,7 +274,7 @@ func TestSyncAllMultipleItems(t *testing.T) {
 		{Src: "config.json", Dst: "config.json", Mode: "copy", When: "always"},
 	}
 
-	if err := SyncAll(srcDir, dstDir, items); err != nil {
+	if _, err := SyncAll(srcDir, dstDir, items, Options{}); err != nil {
 		t.Fatalf("SyncAll failed: %v", err)
 	}
 
@@ -255,7 +307,7 @@ func TestSyncCreatesDestDir(t *testing.T) {
 		When: "always",
 	}
 
-	if err := SyncFile(srcDir, dstDir, item); err != nil {
+	if _, err := SyncFile(srcDir, dstDir, item); err != nil {
 		t.Fatalf("SyncFile failed: %v", err)
 	}
 
@@ -287,7 +339,7 @@ func TestSyncPreservesFileMode(t *testing.T) {
 		When: "always",
 	}
 
-	if err := SyncFile(srcDir, dstDir, item); err != nil {
+	if _, err := SyncFile(srcDir, dstDir, item); err != nil {
 		t.Fatalf("SyncFile failed: %v", err)
 	}
 
@@ -303,3 +355,129 @@ func TestSyncPreservesFileMode(t *testing.T) {
 		t.Errorf("expected executable bit, got %v", info.Mode().Perm())
 	}
 }
+
+func TestSyncFileStatus(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(srcDir, ".env"), []byte("A=1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	copyItem := config.SyncItem{Src: ".env", Dst: ".env", Mode: "copy", When: "always"}
+
+	result, err := SyncFile(srcDir, dstDir, copyItem)
+	if err != nil {
+		t.Fatalf("SyncFile failed: %v", err)
+	}
+	if result.Status != StatusCopied {
+		t.Errorf("expected %s, got %s", StatusCopied, result.Status)
+	}
+
+	// Syncing again is a no-op
+	result, _ = SyncFile(srcDir, dstDir, copyItem)
+	if result.Status != StatusUnchanged {
+		t.Errorf("expected %s, got %s", StatusUnchanged, result.Status)
+	}
+
+	// A changed source is copied again
+	if err := os.WriteFile(filepath.Join(srcDir, ".env"), []byte("A=2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, _ = SyncFile(srcDir, dstDir, copyItem)
+	if result.Status != StatusCopied {
+		t.Errorf("expected %s after change, got %s", StatusCopied, result.Status)
+	}
+
+	result, _ = SyncFile(srcDir, dstDir, config.SyncItem{Src: ".env", Dst: ".env", Mode: "copy", When: "missing"})
+	if result.Status != StatusSkippedExists {
+		t.Errorf("expected %s, got %s", StatusSkippedExists, result.Status)
+	}
+
+	result, _ = SyncFile(srcDir, dstDir, config.SyncItem{Src: "missing", Dst: "missing", Mode: "copy", When: "always"})
+	if result.Status != StatusSkippedMissing {
+		t.Errorf("expected %s, got %s", StatusSkippedMissing, result.Status)
+	}
+
+	linkItem := config.SyncItem{Src: ".env", Dst: ".env.link", Mode: "symlink", When: "always"}
+	result, _ = SyncFile(srcDir, dstDir, linkItem)
+	if result.Status != StatusLinked {
+		t.Errorf("expected %s, got %s", StatusLinked, result.Status)
+	}
+	result, _ = SyncFile(srcDir, dstDir, linkItem)
+	if result.Status != StatusUnchanged {
+		t.Errorf("expected %s for existing link, got %s", StatusUnchanged, result.Status)
+	}
+}
+
+func TestSourceDirs(t *testing.T) {
+	srcDir := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(srcDir, "apps", "web"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "apps", "web", ".env"), []byte("A=1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	items := []config.SyncItem{
+		{Src: ".env"},
+		{Src: "apps/*/.env"},
+		{Src: "missing/dir/.env"},
+	}
+
+	got := SourceDirs(srcDir, items, Options{})
+	want := []string{srcDir, filepath.Join(srcDir, "apps", "web"), filepath.Join(srcDir, "apps")}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, got)
+			break
+		}
+	}
+}
+
+func TestAffected(t *testing.T) {
+	srcDir := t.TempDir()
+
+	for _, rel := range []string{".env", "README.md", "apps/web/.env", "certs/dev.pem", "certs/debug.log"} {
+		path := filepath.Join(srcDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	items := []config.SyncItem{
+		{Src: ".env"},
+		{Src: "apps/*/.env"},
+		{Src: "certs", Exclude: []string{"*.log"}},
+		{Src: "missing.json"},
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{".env", true},
+		{"README.md", false},
+		{"apps/web/.env", true},
+		{"apps/web/index.js", false},
+		{"certs/dev.pem", true},
+		{"certs/debug.log", false},
+		{"missing.json", true}, // Created later
+		{"certs-old/dev.pem", false},
+	}
+	for _, tt := range tests {
+		got, err := Affected(srcDir, items, Options{}, []string{filepath.Join(srcDir, tt.path)})
+		if err != nil {
+			t.Fatalf("Affected failed: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("Affected(%s): expected %v, got %v", tt.path, tt.want, got)
+		}
+	}
+}
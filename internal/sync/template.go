@@ -0,0 +1,115 @@
+package sync
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/Devdha/wm/internal/config"
+)
+
+// TemplateData holds the variables available to mode: template sources
+type TemplateData struct {
+	Branch       string
+	WorktreeName string // Base name of the worktree directory
+	RepoName     string
+	Index        int               // Reserved by wm add, from 1; 0 for the main worktree and worktrees wm did not create
+	Port         int               // First port reserved for the worktree
+	Ports        []int             // Every port reserved for the worktree
+	Env          map[string]string // Environment of the wm process
+}
+
+// NewTemplateData fills Env from the current environment
+func NewTemplateData() *TemplateData {
+	data := &TemplateData{Env: make(map[string]string)}
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			data.Env[k] = v
+		}
+	}
+	return data
+}
+
+// RenderFile writes the source of item, rendered as a text/template with
+// data, to its destination. The destination keeps the source's mode.
+func RenderFile(srcDir, dstDir string, item config.SyncItem, data *TemplateData) (Result, error) {
+	return renderFile(srcDir, dstDir, item, Options{Template: data})
+}
+
+func renderFile(srcDir, dstDir string, item config.SyncItem, opts Options) (Result, error) {
+	srcPath := filepath.Join(srcDir, item.Src)
+	dstPath := filepath.Join(dstDir, item.Dst)
+	result := Result{Src: item.Src, Dst: item.Dst}
+
+	srcInfo, err := os.Stat(srcPath)
+	if os.IsNotExist(err) {
+		result.Status = StatusSkippedMissing
+		return result, nil
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to stat source: %w", err)
+	}
+	if srcInfo.IsDir() {
+		return result, fmt.Errorf("template source %s is a directory", item.Src)
+	}
+
+	if item.When == "missing" {
+		if _, err := os.Stat(dstPath); err == nil {
+			result.Status = StatusSkippedExists
+			return result, nil
+		}
+	}
+
+	if opts.Template == nil {
+		return result, fmt.Errorf("no template variables for %s", item.Src)
+	}
+	rendered, err := renderTemplate(srcPath, opts.Template)
+	if err != nil {
+		return result, err
+	}
+
+	if dstInfo, err := os.Lstat(dstPath); err == nil && dstInfo.Mode().IsRegular() && dstInfo.Mode().Perm() == srcInfo.Mode().Perm() {
+		if existing, err := os.ReadFile(dstPath); err == nil && bytes.Equal(existing, rendered) {
+			result.Status = StatusUnchanged
+			return result, recordSync(item, dstPath, opts)
+		}
+	}
+
+	incoming := func() ([]byte, error) { return rendered, nil }
+	if ok, err := resolveConflict(dstPath, item, incoming, opts, &result); !ok {
+		return result, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+		return result, fmt.Errorf("failed to create dest directory: %w", err)
+	}
+	os.Remove(dstPath)
+	if err := os.WriteFile(dstPath, rendered, srcInfo.Mode().Perm()); err != nil {
+		return result, fmt.Errorf("failed to write %s: %w", item.Dst, err)
+	}
+
+	result.Status = StatusRendered
+	result.Copied = int64(len(rendered))
+	return result, recordSync(item, dstPath, opts)
+}
+
+func renderTemplate(path string, data *TemplateData) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template %s: %w", path, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", path, err)
+	}
+	return buf.Bytes(), nil
+}
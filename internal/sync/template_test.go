@@ -0,0 +1,83 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Devdha/wm/internal/config"
+)
+
+func TestSyncAllTemplate(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+
+	content := `PORT={{.Port}}
+DATABASE_URL=postgres://localhost/{{.RepoName}}_{{.Index}}
+COMPOSE_PROJECT_NAME={{.WorktreeName}}
+BRANCH={{.Branch}}
+USER={{.Env.WM_TEST_USER}}
+`
+	if err := os.WriteFile(filepath.Join(srcDir, ".env.tmpl"), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("WM_TEST_USER", "alice")
+	data := NewTemplateData()
+	data.Branch = "feature/login"
+	data.WorktreeName = "feature-login"
+	data.RepoName = "shop"
+	data.Index = 2
+	data.Port = 3002
+
+	items := []config.SyncItem{{Src: ".env.tmpl", Dst: ".env", Mode: "template", When: "always"}}
+	results, err := SyncAll(srcDir, dstDir, items, Options{Template: data})
+	if err != nil {
+		t.Fatalf("SyncAll failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != StatusRendered {
+		t.Fatalf("expected one rendered result, got %v", results)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dstDir, ".env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `PORT=3002
+DATABASE_URL=postgres://localhost/shop_2
+COMPOSE_PROJECT_NAME=feature-login
+BRANCH=feature/login
+USER=alice
+`
+	if string(got) != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+	if info, _ := os.Stat(filepath.Join(dstDir, ".env")); info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %o", info.Mode().Perm())
+	}
+
+	// Rendering the same variables again leaves the file alone
+	results, err = SyncAll(srcDir, dstDir, items, Options{Template: data})
+	if err != nil || results[0].Status != StatusUnchanged {
+		t.Errorf("expected unchanged, got %v (%v)", results, err)
+	}
+}
+
+func TestRenderFileMissingKey(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(srcDir, "tmpl"), []byte("{{.Env.WM_TEST_UNSET_VARIABLE}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	item := config.SyncItem{Src: "tmpl", Dst: "out", Mode: "template"}
+	_, err := RenderFile(srcDir, dstDir, item, NewTemplateData())
+	if err == nil || !strings.Contains(err.Error(), "WM_TEST_UNSET_VARIABLE") {
+		t.Errorf("expected missing key error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, "out")); !os.IsNotExist(err) {
+		t.Error("expected no output for a failed render")
+	}
+}
@@ -0,0 +1,139 @@
+//go:build linux
+
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_CLOSE_WRITE | syscall.IN_CREATE | syscall.IN_DELETE |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_ATTRIB
+
+type inotifyWatcher struct {
+	file   *os.File
+	events chan string
+	errors chan error
+	done   chan struct{}
+	once   sync.Once
+
+	mu   sync.Mutex
+	dirs map[int]string // watch descriptor -> directory
+	wds  map[string]int
+}
+
+// New returns an inotify based Watcher
+func New() (Watcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify_init failed: %w", err)
+	}
+
+	// A non-blocking fd is served by the runtime poller, so Close unblocks Read
+	w := &inotifyWatcher{
+		file:   os.NewFile(uintptr(fd), "inotify"),
+		events: make(chan string),
+		errors: make(chan error),
+		done:   make(chan struct{}),
+		dirs:   make(map[int]string),
+		wds:    make(map[string]int),
+	}
+	go w.readEvents()
+	return w, nil
+}
+
+func (w *inotifyWatcher) Add(dir string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.wds[dir]; ok {
+		return nil
+	}
+
+	conn, err := w.file.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var wd int
+	var addErr error
+	conn.Control(func(fd uintptr) {
+		wd, addErr = syscall.InotifyAddWatch(int(fd), dir, inotifyMask)
+	})
+	if addErr != nil {
+		return fmt.Errorf("failed to watch %s: %w", dir, addErr)
+	}
+
+	w.dirs[wd] = dir
+	w.wds[dir] = wd
+	return nil
+}
+
+func (w *inotifyWatcher) Events() <-chan string { return w.events }
+
+func (w *inotifyWatcher) Errors() <-chan error { return w.errors }
+
+func (w *inotifyWatcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		err = w.file.Close()
+	})
+	return err
+}
+
+func (w *inotifyWatcher) readEvents() {
+	defer close(w.events)
+	defer close(w.errors)
+
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := w.file.Read(buf)
+		if errors.Is(err, os.ErrClosed) {
+			return
+		}
+		if err != nil {
+			send(w.errors, fmt.Errorf("inotify read failed: %w", err), w.done)
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameBytes := buf[offset+syscall.SizeofInotifyEvent : offset+syscall.SizeofInotifyEvent+int(event.Len)]
+			offset += syscall.SizeofInotifyEvent + int(event.Len)
+
+			if event.Mask&syscall.IN_Q_OVERFLOW != 0 {
+				if !send(w.errors, errors.New("inotify event queue overflowed"), w.done) {
+					return
+				}
+				continue
+			}
+
+			w.mu.Lock()
+			dir, ok := w.dirs[int(event.Wd)]
+			if event.Mask&syscall.IN_IGNORED != 0 {
+				delete(w.dirs, int(event.Wd))
+				delete(w.wds, dir)
+			}
+			w.mu.Unlock()
+			if !ok {
+				continue
+			}
+
+			// The name is NUL padded to an alignment boundary
+			name := strings.TrimRight(string(nameBytes), "\x00")
+			path := dir
+			if name != "" {
+				path = filepath.Join(dir, name)
+			}
+			if !send(w.events, path, w.done) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+	mode    os.FileMode
+}
+
+// pollWatcher detects changes by rescanning directories periodically
+type pollWatcher struct {
+	events chan string
+	errors chan error
+	done   chan struct{}
+	once   sync.Once
+
+	mu   sync.Mutex
+	dirs map[string]map[string]fileState
+}
+
+// NewPoller returns a Watcher that rescans its directories every interval.
+// It works everywhere but is slower to notice changes than New on Linux.
+func NewPoller(interval time.Duration) Watcher {
+	w := &pollWatcher{
+		events: make(chan string),
+		errors: make(chan error),
+		done:   make(chan struct{}),
+		dirs:   make(map[string]map[string]fileState),
+	}
+	go w.poll(interval)
+	return w
+}
+
+func (w *pollWatcher) Add(dir string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.dirs[dir]; ok {
+		return nil
+	}
+	state, err := scanDir(dir)
+	if err != nil {
+		return err
+	}
+	w.dirs[dir] = state
+	return nil
+}
+
+func (w *pollWatcher) Events() <-chan string { return w.events }
+
+func (w *pollWatcher) Errors() <-chan error { return w.errors }
+
+func (w *pollWatcher) Close() error {
+	w.once.Do(func() { close(w.done) })
+	return nil
+}
+
+func (w *pollWatcher) poll(interval time.Duration) {
+	defer close(w.events)
+	defer close(w.errors)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+
+		for _, path := range w.rescan() {
+			if !send(w.events, path, w.done) {
+				return
+			}
+		}
+	}
+}
+
+// rescan updates the recorded state and returns the paths that changed
+func (w *pollWatcher) rescan() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var changed []string
+	for dir, old := range w.dirs {
+		current, err := scanDir(dir)
+		if err != nil {
+			current = map[string]fileState{}
+		}
+		for name, st := range current {
+			if prev, ok := old[name]; !ok || prev != st {
+				changed = append(changed, filepath.Join(dir, name))
+			}
+		}
+		for name := range old {
+			if _, ok := current[name]; !ok {
+				changed = append(changed, filepath.Join(dir, name))
+			}
+		}
+		w.dirs[dir] = current
+	}
+	return changed
+}
+
+func scanDir(dir string) (map[string]fileState, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	state := make(map[string]fileState, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		state[entry.Name()] = fileState{modTime: info.ModTime(), size: info.Size(), mode: info.Mode()}
+	}
+	return state, nil
+}
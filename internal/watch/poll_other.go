@@ -0,0 +1,13 @@
+//go:build !linux
+
+package watch
+
+import "time"
+
+// pollInterval is how often the polling watcher rescans its directories
+const pollInterval = 500 * time.Millisecond
+
+// New returns a polling Watcher on platforms without inotify support
+func New() (Watcher, error) {
+	return NewPoller(pollInterval), nil
+}
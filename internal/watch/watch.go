@@ -0,0 +1,26 @@
+// Package watch reports file changes inside a set of directories. Linux uses
+// inotify; other platforms fall back to polling.
+package watch
+
+// Watcher reports changes to entries directly inside watched directories.
+// Directories are not watched recursively.
+type Watcher interface {
+	// Add starts watching dir. Adding a directory twice is a no-op.
+	Add(dir string) error
+	// Events delivers the paths of created, modified, moved or removed entries
+	Events() <-chan string
+	// Errors delivers errors that do not stop the watcher
+	Errors() <-chan error
+	// Close stops the watcher and closes its channels
+	Close() error
+}
+
+// send delivers v unless done is closed, reporting whether it was sent
+func send[T any](ch chan<- T, v T, done <-chan struct{}) bool {
+	select {
+	case ch <- v:
+		return true
+	case <-done:
+		return false
+	}
+}
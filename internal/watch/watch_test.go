@@ -0,0 +1,65 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func expectEvent(t *testing.T, w Watcher, want string) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case path := <-w.Events():
+			if path == want {
+				return
+			}
+		case err := <-w.Errors():
+			t.Fatalf("watch error: %v", err)
+		case <-timeout:
+			t.Fatalf("no event for %s", want)
+		}
+	}
+}
+
+func testWatcher(t *testing.T, w Watcher) {
+	dir := t.TempDir()
+	if err := w.Add(dir); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	// Plain write
+	envPath := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envPath, []byte("A=1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(t, w, envPath)
+
+	// Atomic replace, as done by many editors
+	tmpPath := filepath.Join(dir, ".env.tmp")
+	if err := os.WriteFile(tmpPath, []byte("A=22"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmpPath, envPath); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(t, w, envPath)
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	w, err := New()
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	testWatcher(t, w)
+}
+
+func TestPoller(t *testing.T) {
+	testWatcher(t, NewPoller(20*time.Millisecond))
+}
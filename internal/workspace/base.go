@@ -0,0 +1,91 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Devdha/wm/internal/git"
+)
+
+// baseFile holds the Base of a worktree in its state directory
+const baseFile = "base.json"
+
+// Base records what a worktree was created from, so later commands can tell
+// what changed on its branch since
+type Base struct {
+	Ref       string    `json:"ref"`    // e.g. origin/main, or the branch itself if it already existed
+	Commit    string    `json:"commit"` // Commit the worktree was checked out at
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Short returns the abbreviated commit hash
+func (b Base) Short() string {
+	return b.Commit[:min(7, len(b.Commit))]
+}
+
+// String formats the base as "origin/main (1a2b3c4)"
+func (b Base) String() string {
+	if b.Commit == "" {
+		return b.Ref
+	}
+	return fmt.Sprintf("%s (%s)", b.Ref, b.Short())
+}
+
+// LoadBase returns the recorded base of a worktree, or nil for worktrees
+// that were not created by wm
+func LoadBase(wtPath string) (*Base, error) {
+	stateDir, err := StateDir(wtPath)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(stateDir, baseFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read base: %w", err)
+	}
+
+	var base Base
+	if err := json.Unmarshal(data, &base); err != nil {
+		return nil, fmt.Errorf("failed to parse base: %w", err)
+	}
+	return &base, nil
+}
+
+// recordBase stores the commit a new worktree was checked out at along with
+// the ref it came from
+func recordBase(wtPath, ref string) (Base, error) {
+	base := Base{Ref: ref, CreatedAt: time.Now()}
+	commit, err := git.ResolveRef(wtPath, "HEAD")
+	if err != nil {
+		return base, err
+	}
+	base.Commit = commit
+
+	stateDir, err := StateDir(wtPath)
+	if err != nil {
+		return base, err
+	}
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return base, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(base, "", "  ")
+	if err != nil {
+		return base, fmt.Errorf("failed to marshal base: %w", err)
+	}
+	path := filepath.Join(stateDir, baseFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return base, fmt.Errorf("failed to write base: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return base, fmt.Errorf("failed to write base: %w", err)
+	}
+	return base, nil
+}
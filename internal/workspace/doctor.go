@@ -0,0 +1,90 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Devdha/wm/internal/sync"
+)
+
+// Doctor looks for sync symlinks in the worktrees whose target no longer
+// exists, e.g. after the repository was moved, and relinks them to their
+// source once the user confirms, or right away with force
+func (w *Workspace) Doctor(force bool) error {
+	worktrees, err := w.ListWorktrees()
+	if err != nil {
+		return err
+	}
+
+	w.UI.Print("Checking sync symlinks...")
+	var broken []string
+	total := 0
+	for _, wt := range worktrees {
+		if wt.Path == w.Root || wt.Bare {
+			continue
+		}
+		if _, err := os.Stat(wt.Path); err != nil {
+			w.UI.Printf("%s: missing; if it was moved, run 'git worktree repair <new path>' first\n", wt.Path)
+			continue
+		}
+
+		statuses, err := w.SyncStatus(wt.Path)
+		if err != nil {
+			return fmt.Errorf("failed to check %s: %w", wt.Path, err)
+		}
+		var dangling []sync.FileStatus
+		for _, s := range statuses {
+			if s.Mode == "symlink" && s.State == sync.StateDangling {
+				dangling = append(dangling, s)
+			}
+		}
+		if len(dangling) == 0 {
+			continue
+		}
+
+		w.UI.Printf("%s:\n", wt.Path)
+		for _, s := range dangling {
+			w.UI.Printf("  dangling  %s\n", s.Dst)
+		}
+		broken = append(broken, wt.Path)
+		total += len(dangling)
+	}
+
+	if total == 0 {
+		w.UI.Print("No dangling sync symlinks found.")
+		return nil
+	}
+	if !force && !w.UI.Confirm(fmt.Sprintf("Repair %d dangling symlink(s)?", total)) {
+		w.UI.Print("Aborted.")
+		return nil
+	}
+
+	for _, wtPath := range broken {
+		if err := w.repairLinks(wtPath); err != nil {
+			return fmt.Errorf("failed to repair %s: %w", wtPath, err)
+		}
+	}
+	return nil
+}
+
+// repairLinks relinks the dangling sync symlinks of one worktree
+func (w *Workspace) repairLinks(wtPath string) error {
+	opts, manifestPath, err := w.worktreeSyncOptions(wtPath)
+	if err != nil {
+		return err
+	}
+
+	results, err := sync.Repair(w.Root, wtPath, w.Config.Sync, opts)
+	for _, r := range results {
+		switch r.Status {
+		case sync.StatusLinked:
+			w.UI.Printf("  relinked  %s\n", r.Dst)
+		case sync.StatusSkippedMissing:
+			w.UI.Printf("  skipped   %s (%s no longer exists)\n", r.Dst, r.Src)
+		}
+	}
+	if saveErr := opts.Manifest.Save(manifestPath); err == nil {
+		err = saveErr
+	}
+	return err
+}
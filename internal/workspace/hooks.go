@@ -0,0 +1,61 @@
+package workspace
+
+import (
+	"fmt"
+
+	"github.com/Devdha/wm/internal/config"
+	"github.com/Devdha/wm/internal/runner"
+)
+
+// Lifecycle hook names, also exported to hooks as WM_HOOK
+const (
+	HookPreAdd     = "pre_add"
+	HookPostAdd    = "post_add"
+	HookPreRemove  = "pre_remove"
+	HookPostRemove = "post_remove"
+)
+
+func (w *Workspace) hook(name string) config.HookConfig {
+	switch name {
+	case HookPreAdd:
+		return w.Config.Tasks.PreAdd
+	case HookPostAdd:
+		return w.Config.Tasks.PostAdd
+	case HookPreRemove:
+		return w.Config.Tasks.PreRemove
+	case HookPostRemove:
+		return w.Config.Tasks.PostRemove
+	}
+	return config.HookConfig{}
+}
+
+// runHook runs a lifecycle hook in dir. Commands run in the foreground and
+// the first failure stops the hook.
+func (w *Workspace) runHook(name, dir, wtPath, branch string) error {
+	hook := w.hook(name)
+	if len(hook.Commands) == 0 {
+		return nil
+	}
+
+	w.UI.Printf("Running %s hook...\n", name)
+	opts := runner.Options{
+		Shell: w.taskShell(hook.Shell),
+		Env:   append(w.taskEnv(wtPath, branch), "WM_HOOK="+name),
+	}
+	if err := runner.RunCommands(dir, hook.Commands, opts); err != nil {
+		return fmt.Errorf("%s hook failed: %w", name, err)
+	}
+	return nil
+}
+
+// taskEnv returns the WM_* variables describing a worktree, including its
+// reserved ports, for hooks and post-install commands
+func (w *Workspace) taskEnv(wtPath, branch string) []string {
+	env := []string{
+		"WM_WORKTREE_PATH=" + wtPath,
+		"WM_BRANCH=" + branch,
+		"WM_REPO_ROOT=" + w.Root,
+		"WM_REPO_NAME=" + w.Name,
+	}
+	return append(env, w.portEnv(wtPath)...)
+}
@@ -0,0 +1,93 @@
+package workspace
+
+import "time"
+
+// indexFile is the registry of the .Index each worktree is rendered with
+const indexFile = "indexes.json"
+
+// indexEntry is the template index reserved for one worktree
+type indexEntry struct {
+	Index      int       `json:"index"`
+	ReservedAt time.Time `json:"reserved_at"`
+}
+
+// indexRegistry maps worktree paths to their indexes
+type indexRegistry map[string]indexEntry
+
+func (w *Workspace) loadIndexes() (indexRegistry, error) {
+	registry := make(indexRegistry)
+	if err := w.loadRegistry(indexFile, &registry); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// worktreeIndex returns the index reserved for a worktree: 0 for the main
+// worktree and for worktrees wm did not create
+func (w *Workspace) worktreeIndex(wtPath string) int {
+	registry, err := w.loadIndexes()
+	if err != nil {
+		return 0
+	}
+	return registry[canonicalPath(wtPath)].Index
+}
+
+// allocateIndex reserves the lowest unused index from 1 for a worktree, or
+// returns its existing one. An index never changes while its worktree
+// exists; indexes of removed worktrees are reused.
+func (w *Workspace) allocateIndex(wtPath string) (int, error) {
+	unlock, err := w.lockRegistry()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	registry, err := w.loadIndexes()
+	if err != nil {
+		return 0, err
+	}
+
+	key := canonicalPath(wtPath)
+	if entry, ok := registry[key]; ok {
+		return entry.Index, nil
+	}
+
+	// Release indexes of worktrees removed without wm
+	stale := w.stale()
+	used := make(map[int]bool, len(registry))
+	for path, entry := range registry {
+		if stale(path, entry.ReservedAt) {
+			delete(registry, path)
+			continue
+		}
+		used[entry.Index] = true
+	}
+
+	index := 1
+	for used[index] {
+		index++
+	}
+	registry[key] = indexEntry{Index: index, ReservedAt: time.Now()}
+	return index, w.saveRegistry(indexFile, registry)
+}
+
+// freeIndex releases the index of a worktree
+func (w *Workspace) freeIndex(wtPath string) error {
+	unlock, err := w.lockRegistry()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	registry, err := w.loadIndexes()
+	if err != nil {
+		return err
+	}
+
+	key := canonicalPath(wtPath)
+	if _, ok := registry[key]; !ok {
+		return nil
+	}
+	delete(registry, key)
+	return w.saveRegistry(indexFile, registry)
+}
@@ -0,0 +1,36 @@
+package workspace
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestAllocateIndexIsStable(t *testing.T) {
+	ws := setupTestWorkspace(t)
+	base := t.TempDir()
+	one, two, three := filepath.Join(base, "one"), filepath.Join(base, "two"), filepath.Join(base, "three")
+
+	for i, path := range []string{one, two, three} {
+		index, err := ws.allocateIndex(path)
+		if err != nil {
+			t.Fatalf("allocateIndex(%s) failed: %v", path, err)
+		}
+		if index != i+1 {
+			t.Errorf("expected index %d for %s, got %d", i+1, path, index)
+		}
+	}
+
+	// Removing a worktree keeps the others' indexes and frees its own
+	if err := ws.freeIndex(two); err != nil {
+		t.Fatalf("freeIndex failed: %v", err)
+	}
+	if index := ws.worktreeIndex(three); index != 3 {
+		t.Errorf("expected three to keep index 3, got %d", index)
+	}
+	if index, err := ws.allocateIndex(filepath.Join(base, "four")); err != nil || index != 2 {
+		t.Errorf("expected the freed index 2 to be reused, got %d, %v", index, err)
+	}
+	if index := ws.worktreeIndex(ws.Root); index != 0 {
+		t.Errorf("expected index 0 for the main worktree, got %d", index)
+	}
+}
@@ -0,0 +1,109 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Devdha/wm/internal/git"
+)
+
+// maxCollisionSuffix bounds the search for a free directory name
+const maxCollisionSuffix = 100
+
+// branchName applies worktree.branch_prefix to a name given to wm add.
+// Names that already carry the prefix, and branches that already exist
+// locally or on one of worktree.remotes, are used as they are.
+func (w *Workspace) branchName(name string) string {
+	prefix := w.Config.Worktree.BranchPrefix
+	if prefix == "" || strings.HasPrefix(name, prefix) {
+		return name
+	}
+	if git.BranchExists(w.Root, name) || w.findRemoteBranch(name) != "" {
+		return name
+	}
+	return prefix + name
+}
+
+// resolveWorktreePath returns where the worktree for branch goes: customPath
+// if given, else worktree.path_template under worktree.base_dir. If that
+// directory already exists, worktree.on_collision decides between an error
+// and appending a numeric suffix.
+func (w *Workspace) resolveWorktreePath(branch, customPath string) (string, error) {
+	if customPath != "" {
+		if filepath.IsAbs(customPath) {
+			return customPath, nil
+		}
+		cwd, _ := os.Getwd()
+		return filepath.Join(cwd, customPath), nil
+	}
+
+	cfg := w.Config.Worktree
+	baseDir := strings.ReplaceAll(cfg.BaseDir, "{repo}", w.Name)
+	if !filepath.IsAbs(baseDir) {
+		baseDir = filepath.Join(w.Root, baseDir)
+	}
+
+	template := cfg.PathTemplate
+	if template == "" {
+		template = "{branch}"
+	}
+	name := strings.NewReplacer(
+		"{repo}", w.Name,
+		"{branch}", branch,
+		"{branch_slug}", w.slugify(branch),
+	).Replace(template)
+	path := filepath.Join(baseDir, name)
+
+	if _, err := os.Lstat(path); err != nil {
+		return path, nil
+	}
+	if cfg.OnCollision != "suffix" {
+		return "", fmt.Errorf("%s already exists; remove it, pass --path, or set worktree.on_collision: suffix", path)
+	}
+	for i := 2; i <= maxCollisionSuffix; i++ {
+		candidate := fmt.Sprintf("%s%s%d", path, w.Config.Worktree.Slug.Separator, i)
+		if _, err := os.Lstat(candidate); err != nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no free directory name for %s", path)
+}
+
+// slugify turns a branch name into a single directory name following
+// worktree.slug, e.g. "feature/Login page" into "feature-Login-page"
+func (w *Workspace) slugify(branch string) string {
+	cfg := w.Config.Worktree.Slug
+	sep := cfg.Separator
+
+	var b strings.Builder
+	pending := false // A separator is due before the next kept character
+	for _, r := range branch {
+		keep := r == '.' || r == '_' || r == '-' ||
+			(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+		if !keep {
+			pending = b.Len() > 0
+			continue
+		}
+		if pending {
+			b.WriteString(sep)
+			pending = false
+		}
+		b.WriteRune(r)
+	}
+
+	slug := b.String()
+	if cfg.Lowercase {
+		slug = strings.ToLower(slug)
+	}
+	if cfg.MaxLength > 0 && len(slug) > cfg.MaxLength {
+		slug = slug[:cfg.MaxLength]
+	}
+	// Leading dots would hide the directory, trailing ones confuse Windows
+	slug = strings.Trim(slug, ".-_"+sep)
+	if slug == "" {
+		return "worktree"
+	}
+	return slug
+}
@@ -0,0 +1,145 @@
+package workspace
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// portsFile is the registry of port blocks
+const portsFile = "ports.json"
+
+// PortBlock is a contiguous range of ports reserved for one worktree
+type PortBlock struct {
+	Start      int       `json:"start"`
+	Size       int       `json:"size"`
+	ReservedAt time.Time `json:"reserved_at,omitempty"`
+}
+
+// Ports returns every port in the block
+func (b PortBlock) Ports() []int {
+	ports := make([]int, b.Size)
+	for i := range ports {
+		ports[i] = b.Start + i
+	}
+	return ports
+}
+
+// String formats the block as "3100-3109"
+func (b PortBlock) String() string {
+	if b.Size == 1 {
+		return strconv.Itoa(b.Start)
+	}
+	return fmt.Sprintf("%d-%d", b.Start, b.Start+b.Size-1)
+}
+
+// portRegistry maps worktree paths to their port blocks
+type portRegistry map[string]PortBlock
+
+func (w *Workspace) loadPorts() (portRegistry, error) {
+	registry := make(portRegistry)
+	if err := w.loadRegistry(portsFile, &registry); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// Ports returns the port block reserved for a worktree, if any
+func (w *Workspace) Ports(wtPath string) (PortBlock, bool) {
+	registry, err := w.loadPorts()
+	if err != nil {
+		return PortBlock{}, false
+	}
+	block, ok := registry[canonicalPath(wtPath)]
+	return block, ok
+}
+
+// AllocatePorts reserves the lowest free block of ports.block_size ports in
+// the configured range for a worktree, or returns its existing block.
+// Blocks of worktrees that no longer exist are released first. The range
+// is validated when the config is loaded.
+func (w *Workspace) AllocatePorts(wtPath string) (PortBlock, error) {
+	cfg := w.Config.Ports
+
+	unlock, err := w.lockRegistry()
+	if err != nil {
+		return PortBlock{}, err
+	}
+	defer unlock()
+
+	registry, err := w.loadPorts()
+	if err != nil {
+		return PortBlock{}, err
+	}
+
+	key := canonicalPath(wtPath)
+	if block, ok := registry[key]; ok {
+		return block, nil
+	}
+	// Release blocks of worktrees removed without wm
+	stale := w.stale()
+	for path, block := range registry {
+		if stale(path, block.ReservedAt) {
+			delete(registry, path)
+		}
+	}
+
+	used := make([]PortBlock, 0, len(registry))
+	for _, block := range registry {
+		used = append(used, block)
+	}
+	sort.Slice(used, func(i, j int) bool { return used[i].Start < used[j].Start })
+
+	start := cfg.Start
+	for _, block := range used {
+		if start+cfg.BlockSize <= block.Start {
+			break
+		}
+		if next := block.Start + block.Size; next > start {
+			start = next
+		}
+	}
+	if start+cfg.BlockSize-1 > cfg.End {
+		return PortBlock{}, fmt.Errorf("no free block of %d ports left in %d-%d", cfg.BlockSize, cfg.Start, cfg.End)
+	}
+
+	block := PortBlock{Start: start, Size: cfg.BlockSize, ReservedAt: time.Now()}
+	registry[key] = block
+	return block, w.saveRegistry(portsFile, registry)
+}
+
+// FreePorts releases the port block of a worktree
+func (w *Workspace) FreePorts(wtPath string) error {
+	unlock, err := w.lockRegistry()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	registry, err := w.loadPorts()
+	if err != nil {
+		return err
+	}
+
+	key := canonicalPath(wtPath)
+	if _, ok := registry[key]; !ok {
+		return nil
+	}
+	delete(registry, key)
+	return w.saveRegistry(portsFile, registry)
+}
+
+// portEnv returns WM_PORT_0..N for a worktree's reserved ports
+func (w *Workspace) portEnv(wtPath string) []string {
+	block, ok := w.Ports(wtPath)
+	if !ok {
+		return nil
+	}
+
+	env := make([]string, 0, block.Size)
+	for i, port := range block.Ports() {
+		env = append(env, fmt.Sprintf("WM_PORT_%d=%d", i, port))
+	}
+	return env
+}
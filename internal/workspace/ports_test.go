@@ -0,0 +1,75 @@
+package workspace
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/Devdha/wm/internal/ui"
+)
+
+func setupTestWorkspace(t *testing.T) *Workspace {
+	t.Helper()
+	dir := t.TempDir()
+
+	cmds := [][]string{
+		{"git", "init"},
+		{"git", "config", "user.email", "test@test.com"},
+		{"git", "config", "user.name", "Test"},
+		{"git", "commit", "--allow-empty", "-m", "initial"},
+	}
+	for _, args := range cmds {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("command %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	ws, err := OpenAt(dir, ui.NewSilent(true))
+	if err != nil {
+		t.Fatalf("OpenAt failed: %v", err)
+	}
+	return ws
+}
+
+func TestAllocatePortsConcurrently(t *testing.T) {
+	ws := setupTestWorkspace(t)
+	base := t.TempDir()
+
+	const n = 8
+	type result struct {
+		path  string
+		block PortBlock
+		err   error
+	}
+	results := make(chan result, n)
+	for i := 0; i < n; i++ {
+		path := filepath.Join(base, fmt.Sprintf("wt%d", i))
+		go func() {
+			block, err := ws.AllocatePorts(path)
+			results <- result{path, block, err}
+		}()
+	}
+
+	starts := make(map[int]string)
+	for i := 0; i < n; i++ {
+		r := <-results
+		if r.err != nil {
+			t.Fatalf("AllocatePorts(%s) failed: %v", r.path, r.err)
+		}
+		if other, ok := starts[r.block.Start]; ok {
+			t.Errorf("%s and %s both got the block at %d", r.path, other, r.block.Start)
+		}
+		starts[r.block.Start] = r.path
+	}
+
+	// Worktrees that do not exist yet keep their blocks
+	for start, path := range starts {
+		block, ok := ws.Ports(path)
+		if !ok || block.Start != start {
+			t.Errorf("expected %s to keep the block at %d, got %v (%v)", path, start, block, ok)
+		}
+	}
+}
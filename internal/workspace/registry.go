@@ -0,0 +1,151 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Devdha/wm/internal/git"
+)
+
+// Registries record what wm reserved for each worktree, such as ports and
+// template indexes. They are kept in the git common directory so every
+// worktree of the repository sees the same reservations.
+
+// Locking the registries waits up to registryLockTimeout for another wm,
+// and takes over locks older than registryLockStale left by a crashed one
+const (
+	registryLockTimeout = 10 * time.Second
+	registryLockStale   = 30 * time.Second
+)
+
+// registryGracePeriod keeps reservations of worktrees that wm add is still
+// creating from being pruned by a concurrent reservation
+const registryGracePeriod = time.Hour
+
+func (w *Workspace) registryPath(name string) (string, error) {
+	commonDir, err := git.GetCommonDir(w.Root)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(commonDir, "wm", name), nil
+}
+
+// loadRegistry decodes the registry file name into v, leaving v alone if
+// the file does not exist yet
+func (w *Workspace) loadRegistry(name string, v any) error {
+	path, err := w.registryPath(name)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
+
+func (w *Workspace) saveRegistry(name string, v any) error {
+	path, err := w.registryPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// lockRegistry serialises changes to the registries between wm processes.
+// The returned function releases the lock.
+func (w *Workspace) lockRegistry() (func(), error) {
+	lockPath, err := w.registryPath("registry.lock")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	deadline := time.Now().Add(registryLockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock registry: %w", err)
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > registryLockStale {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("registry is locked; remove %s if no other wm is running", lockPath)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// stale returns a function reporting whether a reservation made at
+// reservedAt for path belongs to a worktree that was removed without wm,
+// e.g. with git worktree remove. Reservations younger than
+// registryGracePeriod are kept, as their worktree may still be being
+// created. Nothing is stale if the worktrees cannot be listed.
+func (w *Workspace) stale() func(path string, reservedAt time.Time) bool {
+	worktrees, err := w.ListWorktrees()
+	if err != nil {
+		return func(string, time.Time) bool { return false }
+	}
+
+	live := make(map[string]bool, len(worktrees))
+	for _, wt := range worktrees {
+		live[canonicalPath(wt.Path)] = true
+	}
+	return func(path string, reservedAt time.Time) bool {
+		return !live[path] && time.Since(reservedAt) > registryGracePeriod
+	}
+}
+
+// canonicalPath resolves symlinks in the longest existing prefix of path, so
+// a worktree keeps the same registry key before and after it is created
+func canonicalPath(path string) string {
+	path, _ = filepath.Abs(path)
+
+	var missing []string
+	dir := path
+	for {
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return path
+		}
+		missing = append([]string{filepath.Base(dir)}, missing...)
+		dir = parent
+	}
+}
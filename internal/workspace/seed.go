@@ -0,0 +1,94 @@
+package workspace
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Devdha/wm/internal/config"
+	"github.com/Devdha/wm/internal/detect"
+	"github.com/Devdha/wm/internal/sync"
+)
+
+// seedDependencies clones installed dependency directories such as
+// node_modules from the repo root into a new worktree, so post-install only
+// has to catch up instead of installing from scratch. Seeding is skipped
+// when the worktree's lockfiles differ from the root's. Failures are
+// reported but never abort wm add, since post-install still installs.
+func (w *Workspace) seedDependencies(wtPath string) {
+	seed := w.Config.Seed
+	if !seed.Enabled {
+		return
+	}
+
+	detected := detect.Detect(w.Root)
+	dirs := seed.Dirs
+	if len(dirs) == 0 {
+		dirs = detected.DependencyDirs
+	}
+	lockfiles := seed.Lockfiles
+	if len(lockfiles) == 0 {
+		lockfiles = detected.Lockfiles
+	}
+
+	if len(dirs) == 0 || len(lockfiles) == 0 {
+		w.UI.Print("Skipping seed: no dependency directories or lockfiles configured or detected.")
+		return
+	}
+
+	rootKey, err := lockfileKey(w.Root, lockfiles)
+	if err != nil {
+		w.UI.Printf("Skipping seed: %v\n", err)
+		return
+	}
+	wtKey, err := lockfileKey(wtPath, lockfiles)
+	if err != nil || wtKey != rootKey {
+		w.UI.Printf("Skipping seed: %s differs from the main worktree.\n", strings.Join(lockfiles, ", "))
+		return
+	}
+
+	mode := seed.Mode
+	if mode == "" {
+		mode = "reflink"
+	}
+	items := make([]config.SyncItem, len(dirs))
+	for i, dir := range dirs {
+		items[i] = config.SyncItem{Src: dir, Dst: dir, Mode: mode, When: "always"}
+	}
+
+	w.UI.Printf("Seeding %s from the main worktree...\n", strings.Join(dirs, ", "))
+	results, err := sync.SyncAll(w.Root, wtPath, items, w.syncOptions())
+	if err != nil {
+		w.UI.Printf("Seeding failed, dependencies will be installed from scratch: %v\n", err)
+		return
+	}
+	w.UI.Printf("Seeded %s.\n", sync.Summarize(results))
+}
+
+// lockfileKey hashes the lockfiles in dir, so two checkouts with the same key
+// resolve to the same dependencies. At least one lockfile must exist.
+func lockfileKey(dir string, lockfiles []string) (string, error) {
+	h := sha256.New()
+	found := false
+	for _, name := range lockfiles {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			fmt.Fprintf(h, "%s\x00missing\x00", name)
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		found = true
+		fmt.Fprintf(h, "%s\x00%d\x00", name, len(data))
+		h.Write(data)
+	}
+
+	if !found {
+		return "", fmt.Errorf("no lockfile found (looked for %s)", strings.Join(lockfiles, ", "))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,126 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Devdha/wm/internal/git"
+	"github.com/Devdha/wm/internal/runner"
+)
+
+// WorktreeStatus is a snapshot of one worktree as shown by wm status
+type WorktreeStatus struct {
+	git.Worktree
+	Git         *git.Status // nil if the worktree could not be inspected
+	Base        *Base       // What the worktree is compared against; nil if not compared
+	BaseAhead   int         // Commits not in Base
+	BaseBehind  int         // Base commits not merged yet
+	Subject     string      // Subject of the last commit
+	CommittedAt time.Time
+	Tasks       string // State of the post-install job; empty if it never ran
+	Err         error  // Why the worktree could not be inspected
+}
+
+// Status inspects every worktree in parallel. A worktree is compared
+// against the base recorded when wm created it, or else against
+// worktree.default_base or the main worktree's branch or detached HEAD.
+func (w *Workspace) Status() ([]WorktreeStatus, error) {
+	worktrees, err := w.ListWorktrees()
+	if err != nil {
+		return nil, err
+	}
+
+	base := w.compareBase()
+	root := resolvePath(w.Root)
+
+	statuses := make([]WorktreeStatus, len(worktrees))
+	done := make(chan struct{})
+	for i, wt := range worktrees {
+		go func(i int, wt git.Worktree) {
+			statuses[i] = w.worktreeStatus(wt, base, resolvePath(wt.Path) == root)
+			done <- struct{}{}
+		}(i, wt)
+	}
+	for range worktrees {
+		<-done
+	}
+	return statuses, nil
+}
+
+// compareBase returns worktree.default_base, or the main worktree's branch
+// or detached HEAD, resolved to a commit in the main worktree. Resolving it
+// there matters: "HEAD" means something else inside every worktree.
+func (w *Workspace) compareBase() *Base {
+	ref := w.Config.Worktree.DefaultBase
+	if ref == "" {
+		ref = w.headName()
+	}
+	commit, err := git.ResolveRef(w.Root, ref)
+	if err != nil {
+		return nil
+	}
+	return &Base{Ref: ref, Commit: commit}
+}
+
+// recordedBase returns the base recorded when wm created the worktree, with
+// its ref resolved again so commits added to it since count as behind.
+// Bases that are the worktree's own branch or upstream are ignored, as they
+// tell nothing the upstream counts do not, and so is a detached HEAD, which
+// only names the commit the worktree started at.
+func (w *Workspace) recordedBase(wt git.Worktree, upstream string) *Base {
+	recorded, err := LoadBase(wt.Path)
+	if err != nil || recorded == nil {
+		return nil
+	}
+	switch recorded.Ref {
+	case wt.Branch, upstream, "HEAD":
+		return nil
+	}
+
+	if commit, err := git.ResolveRef(w.Root, recorded.Ref); err == nil {
+		recorded.Commit = commit
+	}
+	if recorded.Commit == "" {
+		return nil
+	}
+	return recorded
+}
+
+func (w *Workspace) worktreeStatus(wt git.Worktree, base *Base, main bool) WorktreeStatus {
+	status := WorktreeStatus{Worktree: wt}
+	if _, err := os.Stat(wt.Path); err != nil {
+		status.Err = fmt.Errorf("missing; run 'git worktree prune'")
+		return status
+	}
+
+	gitStatus, err := git.GetStatus(wt.Path)
+	if err != nil {
+		status.Err = err
+		return status
+	}
+	status.Git = gitStatus
+
+	// Empty repositories have no commits to describe or compare
+	if subject, when, err := git.LastCommit(wt.Path); err == nil {
+		status.Subject = subject
+		status.CommittedAt = when
+	}
+	if recorded := w.recordedBase(wt, gitStatus.Upstream); recorded != nil {
+		base = recorded
+	}
+	if base != nil && !main {
+		if ahead, behind, err := git.AheadBehind(wt.Path, "HEAD", base.Commit); err == nil {
+			status.Base = base
+			status.BaseAhead, status.BaseBehind = ahead, behind
+		}
+	}
+
+	if stateDir, err := StateDir(wt.Path); err == nil {
+		if job, err := runner.LoadJob(stateDir, PostInstallJob); err == nil {
+			job.Reconcile()
+			status.Tasks = job.Status()
+		}
+	}
+	return status
+}
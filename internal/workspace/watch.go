@@ -0,0 +1,104 @@
+package workspace
+
+import (
+	"time"
+
+	"github.com/Devdha/wm/internal/git"
+	"github.com/Devdha/wm/internal/sync"
+	"github.com/Devdha/wm/internal/watch"
+)
+
+// WatchSync keeps the worktrees returned by targets in sync with the repo
+// root until stop is closed. Changes are debounced so an editor's save
+// sequence results in a single sync, changes to files that are not synced
+// are ignored, and every file that was pushed is reported through the
+// Prompter.
+func (w *Workspace) WatchSync(targets func() ([]git.Worktree, error), debounce time.Duration, stop <-chan struct{}) error {
+	watcher, err := watch.New()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	w.watchSourceDirs(watcher)
+	w.UI.Print("Watching sync sources. Press Ctrl+C to stop.")
+
+	// Push the current state once so every worktree starts in sync
+	if err := w.pushSync(targets); err != nil {
+		return err
+	}
+
+	var timer <-chan time.Time
+	var changed []string
+	for {
+		select {
+		case <-stop:
+			return nil
+		case path, ok := <-watcher.Events():
+			if !ok {
+				return nil
+			}
+			changed = append(changed, path)
+			timer = time.After(debounce)
+		case err, ok := <-watcher.Errors():
+			if !ok {
+				return nil
+			}
+			w.UI.Printf("%s watch error: %v\n", timestamp(), err)
+		case <-timer:
+			timer = nil
+			affected, err := sync.Affected(w.Root, w.Config.Sync, w.syncOptions(), changed)
+			changed = nil
+			if err != nil {
+				w.UI.Printf("%s %v\n", timestamp(), err)
+			}
+			// Sync anyway if the sources could not be expanded, to report why
+			if affected || err != nil {
+				if err := w.pushSync(targets); err != nil {
+					w.UI.Printf("%s %v\n", timestamp(), err)
+				}
+			}
+			// Globs may now match files in new directories
+			w.watchSourceDirs(watcher)
+		}
+	}
+}
+
+func (w *Workspace) watchSourceDirs(watcher watch.Watcher) {
+	for _, dir := range sync.SourceDirs(w.Root, w.Config.Sync, w.syncOptions()) {
+		if err := watcher.Add(dir); err != nil {
+			w.UI.Printf("%s %v\n", timestamp(), err)
+		}
+	}
+}
+
+// pushSync syncs every target and reports the files that were written.
+// A worktree that fails to sync is reported and the others are still
+// synced; only failing to resolve the targets is returned.
+func (w *Workspace) pushSync(targets func() ([]git.Worktree, error)) error {
+	worktrees, err := targets()
+	if err != nil {
+		return err
+	}
+
+	for _, wt := range worktrees {
+		if wt.Prunable {
+			continue // Deleted; nothing to sync until it is pruned
+		}
+		// Nobody is there to answer prompts, so conflicts keep local edits
+		results, err := w.syncWorktree(wt.Path, false)
+		for _, r := range results {
+			if r.Changed() || r.Status == sync.StatusConflict {
+				w.UI.Printf("%s %s -> %s (%s)\n", timestamp(), r.Src, wt.Path, r.Status)
+			}
+		}
+		if err != nil {
+			w.UI.Printf("%s failed to sync %s: %v\n", timestamp(), wt.Path, err)
+		}
+	}
+	return nil
+}
+
+func timestamp() string {
+	return time.Now().Format("[15:04:05]")
+}
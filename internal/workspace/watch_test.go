@@ -0,0 +1,44 @@
+package workspace
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/Devdha/wm/internal/config"
+	"github.com/Devdha/wm/internal/git"
+)
+
+func TestPushSyncContinuesAfterFailure(t *testing.T) {
+	ws := setupTestWorkspace(t)
+	ws.Config.Sync = []config.SyncItem{{Src: ".env", Dst: ".env", Mode: "copy", When: "always"}}
+	if err := os.WriteFile(filepath.Join(ws.Root, ".env"), []byte("A=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	base := t.TempDir()
+	var worktrees []git.Worktree
+	for _, name := range []string{"broken", "ok"} {
+		path := filepath.Join(base, name)
+		cmd := exec.Command("git", "worktree", "add", "-b", name, path)
+		cmd.Dir = ws.Root
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git worktree add failed: %v\n%s", err, out)
+		}
+		worktrees = append(worktrees, git.Worktree{Path: path, Branch: name})
+	}
+	// A directory in the way of the file makes the first worktree fail
+	if err := os.MkdirAll(filepath.Join(base, "broken", ".env", "nested"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	worktrees = append(worktrees, git.Worktree{Path: filepath.Join(base, "gone"), Prunable: true})
+
+	targets := func() ([]git.Worktree, error) { return worktrees, nil }
+	if err := ws.pushSync(targets); err != nil {
+		t.Fatalf("pushSync failed: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(base, "ok", ".env")); err != nil || string(data) != "A=1\n" {
+		t.Errorf("expected ok to be synced after the failure, got %q, %v", data, err)
+	}
+}
@@ -3,7 +3,9 @@
 package workspace
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -15,6 +17,9 @@ import (
 	"github.com/Devdha/wm/internal/ui"
 )
 
+// PostInstallJob is the name of the background job running post_install
+const PostInstallJob = "post_install"
+
 // Workspace represents a git repository with WM configuration
 type Workspace struct {
 	Root   string         // Repository root path
@@ -32,14 +37,23 @@ func Open(ui ui.Prompter) (*Workspace, error) {
 	return OpenAt(cwd, ui)
 }
 
-// OpenAt creates a Workspace from a specific directory
+// OpenAt creates a Workspace from a specific directory. Inside a linked
+// worktree the Workspace still refers to the main worktree, which holds
+// .wm.yaml and the files synced into every worktree.
 func OpenAt(dir string, prompter ui.Prompter) (*Workspace, error) {
-	root, err := git.GetRepoRoot(dir)
+	if _, err := git.GetRepoRoot(dir); err != nil {
+		return nil, err
+	}
+
+	root, err := git.GetMainWorktree(dir)
 	if err != nil {
 		return nil, err
 	}
 
-	cfg := loadConfigOrDefault(root)
+	cfg, err := loadConfigOrDefault(root)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Workspace{
 		Root:   root,
@@ -49,12 +63,31 @@ func OpenAt(dir string, prompter ui.Prompter) (*Workspace, error) {
 	}, nil
 }
 
-func loadConfigOrDefault(root string) *config.Config {
+// StateDir returns the directory where wm keeps per-worktree state such as
+// background job status and logs. It lives inside the worktree's git
+// directory (.git/worktrees/<name>/wm) so it is never committed and goes
+// away with the worktree.
+func StateDir(wtPath string) (string, error) {
+	gitDir, err := git.GetGitDir(wtPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(gitDir, "wm"), nil
+}
+
+// loadConfigOrDefault reads .wm.yaml, using defaults only if it does not
+// exist. An invalid file is an error: falling back would silently disable
+// the hooks and sync items it configures.
+func loadConfigOrDefault(root string) (*config.Config, error) {
 	configPath := filepath.Join(root, config.ConfigFileName)
-	if cfg, err := config.LoadConfig(configPath); err == nil {
-		return cfg
+	cfg, err := config.LoadConfig(configPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config.NewConfig(), nil
 	}
-	return config.NewConfig()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", configPath, err)
+	}
+	return cfg, nil
 }
 
 // ListWorktrees returns all worktrees in this workspace
@@ -62,52 +95,239 @@ func (w *Workspace) ListWorktrees() ([]git.Worktree, error) {
 	return git.ListWorktrees(w.Root)
 }
 
-// AddWorktree creates a new worktree with optional sync and post-install
-func (w *Workspace) AddWorktree(branch string, customPath string) error {
-	wtPath := w.resolveWorktreePath(branch, customPath)
+// AddOptions controls how AddWorktree creates a worktree
+type AddOptions struct {
+	Path          string // Custom worktree path; empty uses worktree.base_dir
+	KeepOnFailure bool   // Leave a half-initialised worktree in place for debugging
+	Fetch         bool   // Fetch worktree.remotes before looking for the branch
+	From          string // Start a new branch here instead of worktree.default_base
+}
+
+// AddWorktree creates a new worktree with optional sync and post-install.
+// A branch that only exists on one of worktree.remotes is created locally,
+// tracking the remote branch. Otherwise a new branch starts at opts.From,
+// worktree.default_base or the main worktree's HEAD, after confirmation.
+// The commit the worktree starts at is recorded, see LoadBase.
+// If initialising the worktree fails, the worktree and any branch created
+// for it are rolled back unless opts.KeepOnFailure is set.
+// It returns the path of the new worktree, or "" if the user aborted.
+func (w *Workspace) AddWorktree(branch string, opts AddOptions) (string, error) {
+	if opts.Fetch {
+		w.fetchRemotes()
+	}
+	if name := w.branchName(branch); name != branch {
+		w.UI.Printf("Using branch '%s'.\n", name)
+		branch = name
+	}
+	wtPath, err := w.resolveWorktreePath(branch, opts.Path)
+	if err != nil {
+		return "", err
+	}
+
 	createBranch := !git.BranchExists(w.Root, branch)
+	if !createBranch && opts.From != "" {
+		return "", fmt.Errorf("branch '%s' already exists; --from only applies to new branches", branch)
+	}
 
-	if createBranch {
-		msg := fmt.Sprintf("Branch '%s' does not exist. Create it?", branch)
+	// An explicit --from always starts a new branch rather than tracking
+	var upstream, base string
+	if createBranch && opts.From == "" {
+		upstream = w.findRemoteBranch(branch)
+	}
+	if createBranch && upstream == "" {
+		if base, err = w.resolveBase(opts.From); err != nil {
+			return "", err
+		}
+	}
+
+	switch {
+	case upstream != "":
+		w.UI.Printf("Branch '%s' found as %s; creating a local branch that tracks it.\n", branch, upstream)
+	case createBranch:
+		from := base
+		if from == "" {
+			from = w.headName()
+		}
+		msg := fmt.Sprintf("Branch '%s' does not exist locally%s. Create it from %s?", branch, w.remotesNote(), from)
 		if !w.UI.Confirm(msg) {
 			w.UI.Print("Aborted.")
-			return nil
+			return "", nil
 		}
 	}
 
+	// Reserve the index and ports first so every hook and template sees the
+	// same values
+	if _, err := w.allocateIndex(wtPath); err != nil {
+		return "", err
+	}
+	if w.Config.Ports.Enabled {
+		if _, err := w.AllocatePorts(wtPath); err != nil {
+			w.freeIndex(wtPath)
+			return "", err
+		}
+	}
+
+	// The worktree does not exist yet, so pre_add runs from the repo root
+	if err := w.runHook(HookPreAdd, w.Root, wtPath, branch); err != nil {
+		w.FreePorts(wtPath)
+		w.freeIndex(wtPath)
+		return "", fmt.Errorf("worktree not created: %w", err)
+	}
+
 	w.UI.Printf("Creating worktree at %s...\n", wtPath)
-	if err := git.AddWorktree(w.Root, wtPath, branch, createBranch); err != nil {
-		return err
+	switch {
+	case upstream != "":
+		err = git.AddWorktreeFrom(w.Root, wtPath, branch, upstream, true)
+	case base != "":
+		// A new branch must not inherit e.g. origin/main as its upstream
+		err = git.AddWorktreeFrom(w.Root, wtPath, branch, base, false)
+	default:
+		err = git.AddWorktree(w.Root, wtPath, branch, createBranch)
+	}
+	if err != nil {
+		w.FreePorts(wtPath)
+		w.freeIndex(wtPath)
+		return "", err
+	}
+	w.reportBase(wtPath, branch, createBranch, upstream, base)
+
+	if err := w.initWorktree(wtPath, branch); err != nil {
+		if opts.KeepOnFailure {
+			w.UI.Printf("Keeping worktree at %s for debugging.\n", wtPath)
+		} else {
+			w.rollbackAdd(wtPath, branch, createBranch)
+		}
+		return "", err
+	}
+
+	w.UI.Printf("\nWorktree ready: %s\n", wtPath)
+	return wtPath, nil
+}
+
+// fetchRemotes updates the remote-tracking branches of worktree.remotes.
+// Failures, e.g. when offline, only produce a warning.
+func (w *Workspace) fetchRemotes() {
+	for _, remote := range w.Config.Worktree.Remotes {
+		w.UI.Printf("Fetching %s...\n", remote)
+		if err := git.Fetch(w.Root, remote); err != nil {
+			w.UI.Printf("Warning: %v\n", err)
+		}
+	}
+}
+
+// findRemoteBranch returns the first remote branch, e.g. origin/feature,
+// with the given name on one of worktree.remotes, or ""
+func (w *Workspace) findRemoteBranch(branch string) string {
+	for _, remote := range w.Config.Worktree.Remotes {
+		if git.RemoteBranchExists(w.Root, remote, branch) {
+			return remote + "/" + branch
+		}
+	}
+	return ""
+}
+
+// remotesNote names the remotes that were searched for a missing branch
+func (w *Workspace) remotesNote() string {
+	if len(w.Config.Worktree.Remotes) == 0 {
+		return ""
+	}
+	return " or on " + strings.Join(w.Config.Worktree.Remotes, ", ")
+}
+
+// headName returns the branch checked out in the main worktree, which new
+// branches start from, or HEAD if it is detached
+func (w *Workspace) headName() string {
+	if branch, err := git.GetCurrentBranch(w.Root); err == nil && branch != "" {
+		return branch
+	}
+	return "HEAD"
+}
+
+// resolveBase returns the start point for a new branch: from, or else
+// worktree.default_base, or "" for the main worktree's HEAD. The ref must
+// exist.
+func (w *Workspace) resolveBase(from string) (string, error) {
+	if from != "" {
+		if _, err := git.ResolveRef(w.Root, from); err != nil {
+			return "", fmt.Errorf("--from: %w", err)
+		}
+		return from, nil
+	}
+
+	base := w.Config.Worktree.DefaultBase
+	if base == "" {
+		return "", nil
+	}
+	if _, err := git.ResolveRef(w.Root, base); err != nil {
+		return "", fmt.Errorf("worktree.default_base: %w (fetch it or change %s)", err, config.ConfigFileName)
 	}
-	w.UI.Print("Worktree created.")
+	return base, nil
+}
 
+// reportBase records and prints what a new worktree was checked out from.
+// Failing to record it is not fatal.
+func (w *Workspace) reportBase(wtPath, branch string, createdBranch bool, upstream, base string) {
+	ref := branch
+	switch {
+	case upstream != "":
+		ref = upstream
+	case base != "":
+		ref = base
+	case createdBranch:
+		ref = w.headName()
+	}
+
+	recorded, err := recordBase(wtPath, ref)
+	if err != nil {
+		w.UI.Printf("Warning: failed to record the base commit: %v\n", err)
+	}
+	if createdBranch {
+		w.UI.Printf("Worktree created on new branch '%s', based on %s.\n", branch, recorded)
+	} else {
+		w.UI.Printf("Worktree created on existing branch %s.\n", recorded)
+	}
+}
+
+// initWorktree runs everything that happens after git created the worktree
+func (w *Workspace) initWorktree(wtPath, branch string) error {
 	if err := w.syncFiles(wtPath); err != nil {
 		return err
 	}
 
-	if err := w.runPostInstall(wtPath); err != nil {
+	w.seedDependencies(wtPath)
+
+	if err := w.runHook(HookPostAdd, wtPath, wtPath, branch); err != nil {
 		return err
 	}
 
-	w.UI.Printf("\nWorktree ready: %s\n", wtPath)
-	w.UI.Printf("  cd %s\n", wtPath)
-	return nil
+	return w.runPostInstall(wtPath, branch)
 }
 
-func (w *Workspace) resolveWorktreePath(branch, customPath string) string {
-	if customPath != "" {
-		if filepath.IsAbs(customPath) {
-			return customPath
-		}
-		cwd, _ := os.Getwd()
-		return filepath.Join(cwd, customPath)
+// rollbackAdd undoes a failed AddWorktree. The branch is only deleted when
+// wm created it, so pre-existing work is never lost.
+func (w *Workspace) rollbackAdd(wtPath, branch string, createdBranch bool) {
+	w.UI.Print("Rolling back...")
+
+	if err := git.RemoveWorktree(w.Root, wtPath, true); err != nil {
+		w.UI.Printf("  Failed to remove worktree %s: %v\n", wtPath, err)
+	} else {
+		w.UI.Printf("  Removed worktree %s\n", wtPath)
+	}
+	if err := w.FreePorts(wtPath); err != nil {
+		w.UI.Printf("  Failed to release ports: %v\n", err)
+	}
+	if err := w.freeIndex(wtPath); err != nil {
+		w.UI.Printf("  Failed to release index: %v\n", err)
 	}
 
-	baseDir := strings.ReplaceAll(w.Config.Worktree.BaseDir, "{repo}", w.Name)
-	if !filepath.IsAbs(baseDir) {
-		baseDir = filepath.Join(w.Root, baseDir)
+	if !createdBranch {
+		return
+	}
+	if err := git.DeleteBranch(w.Root, branch, true); err != nil {
+		w.UI.Printf("  Failed to delete branch '%s': %v\n", branch, err)
+	} else {
+		w.UI.Printf("  Deleted branch '%s'\n", branch)
 	}
-	return filepath.Join(baseDir, branch)
 }
 
 func (w *Workspace) syncFiles(wtPath string) error {
@@ -116,33 +336,293 @@ func (w *Workspace) syncFiles(wtPath string) error {
 	}
 
 	w.UI.Print("Syncing files...")
-	if err := sync.SyncAll(w.Root, wtPath, w.Config.Sync); err != nil {
+	results, err := w.SyncWorktree(wtPath)
+	if err != nil {
 		return fmt.Errorf("failed to sync files: %w", err)
 	}
-	w.UI.Printf("Synced %d file(s).\n", len(w.Config.Sync))
+
+	w.UI.Printf("Synced %s.\n", sync.Summarize(results))
+	return nil
+}
+
+// SyncWorktree copies the configured sync items from the repo root into an
+// existing worktree, honouring each item's mode and when settings
+func (w *Workspace) SyncWorktree(wtPath string) ([]sync.Result, error) {
+	return w.syncWorktree(wtPath, true)
+}
+
+// syncWorktree syncs into wtPath, recording what was written in the
+// worktree's sync manifest. Files edited since their last sync are resolved
+// per item; prompting is only possible when interactive.
+func (w *Workspace) syncWorktree(wtPath string, interactive bool) ([]sync.Result, error) {
+	opts, manifestPath, err := w.worktreeSyncOptions(wtPath)
+	if err != nil {
+		return nil, err
+	}
+	if interactive {
+		opts.Resolve = w.resolveConflict
+	}
+
+	results, err := sync.SyncAll(w.Root, wtPath, w.Config.Sync, opts)
+	if saveErr := opts.Manifest.Save(manifestPath); err == nil {
+		err = saveErr
+	}
+	return results, err
+}
+
+// SyncStatus reports how each file synced into a worktree compares to its
+// source in the repo root, without changing anything
+func (w *Workspace) SyncStatus(wtPath string) ([]sync.FileStatus, error) {
+	opts, _, err := w.worktreeSyncOptions(wtPath)
+	if err != nil {
+		return nil, err
+	}
+	return sync.Check(w.Root, wtPath, w.Config.Sync, opts)
+}
+
+// worktreeSyncOptions returns the options for syncing into wtPath, with its
+// template variables and sync manifest, and where the manifest is saved
+func (w *Workspace) worktreeSyncOptions(wtPath string) (sync.Options, string, error) {
+	data, err := w.TemplateData(wtPath)
+	if err != nil {
+		return sync.Options{}, "", err
+	}
+	manifestPath, err := syncManifestPath(wtPath)
+	if err != nil {
+		return sync.Options{}, "", err
+	}
+	manifest, err := sync.LoadManifest(manifestPath)
+	if err != nil {
+		return sync.Options{}, "", err
+	}
+
+	opts := w.syncOptions()
+	opts.Template = data
+	opts.Manifest = manifest
+	return opts, manifestPath, nil
+}
+
+// syncManifestPath returns where the hashes of files synced into a
+// worktree are kept
+func syncManifestPath(wtPath string) (string, error) {
+	stateDir, err := StateDir(wtPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "sync.json"), nil
+}
+
+// resolveConflict shows how a locally edited file differs from what sync
+// would write and asks what to do with it
+func (w *Workspace) resolveConflict(c sync.Conflict) string {
+	w.UI.Printf("%s was edited since it was last synced:\n%s", c.Dst, c.Diff)
+	for {
+		switch w.UI.Input("[o]verwrite, [s]kip or [b]ackup and overwrite?", "s") {
+		case "o", "overwrite":
+			return sync.ConflictOverwrite
+		case "s", "skip":
+			return sync.ConflictSkip
+		case "b", "backup":
+			return sync.ConflictBackup
+		}
+	}
+}
+
+// SyncFrom copies the sync items from a worktree back into other worktrees,
+// usually the root, e.g. after editing .env on a feature branch. The changes
+// are shown as diffs and only applied once the user confirms, unless force
+// is set.
+func (w *Workspace) SyncFrom(srcPath string, dstPaths []string, force bool) error {
+	opts := w.syncOptions()
+
+	total := 0
+	for _, dstPath := range dstPaths {
+		changes, err := sync.Preview(srcPath, dstPath, w.itemsFrom(dstPath), opts)
+		if err != nil {
+			return fmt.Errorf("failed to compare with %s: %w", dstPath, err)
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		w.UI.Printf("%s:\n", dstPath)
+		for _, change := range changes {
+			status := "modified"
+			if change.Added {
+				status = "added"
+			}
+			w.UI.Printf("  %-9s %s\n", status, change.Dst)
+			w.UI.Printf("%s", change.Diff)
+		}
+		total += len(changes)
+	}
+
+	if total == 0 {
+		w.UI.Print("Nothing to sync, destinations are up to date.")
+		return nil
+	}
+	if !force && !w.UI.Confirm(fmt.Sprintf("Apply %d change(s)?", total)) {
+		w.UI.Print("Aborted.")
+		return nil
+	}
+
+	for _, dstPath := range dstPaths {
+		if err := w.syncInto(srcPath, dstPath, opts); err != nil {
+			return fmt.Errorf("failed to sync into %s: %w", dstPath, err)
+		}
+	}
 	return nil
 }
 
-func (w *Workspace) runPostInstall(wtPath string) error {
-	cmds := w.Config.Tasks.PostInstall.Commands
-	if len(cmds) == 0 {
+// itemsFrom returns the sync items for copying from a worktree to dstPath
+func (w *Workspace) itemsFrom(dstPath string) []config.SyncItem {
+	if dstPath == w.Root {
+		return sync.Reverse(w.Config.Sync)
+	}
+	return sync.Peer(w.Config.Sync)
+}
+
+// syncInto applies a confirmed SyncFrom to one destination. Worktree
+// manifests are updated so the new content does not look like local edits.
+func (w *Workspace) syncInto(srcPath, dstPath string, opts sync.Options) error {
+	var manifestPath string
+	if dstPath != w.Root {
+		path, err := syncManifestPath(dstPath)
+		if err != nil {
+			return err
+		}
+		manifest, err := sync.LoadManifest(path)
+		if err != nil {
+			return err
+		}
+		manifestPath, opts.Manifest = path, manifest
+	}
+
+	results, err := sync.SyncAll(srcPath, dstPath, w.itemsFrom(dstPath), opts)
+	if err != nil {
+		return err
+	}
+	w.UI.Printf("Synced %s into %s.\n", sync.Summarize(results), dstPath)
+
+	if opts.Manifest == nil {
+		return nil
+	}
+	return opts.Manifest.Save(manifestPath)
+}
+
+// TemplateData returns the variables that mode: template sync items are
+// rendered with for a worktree
+func (w *Workspace) TemplateData(wtPath string) (*sync.TemplateData, error) {
+	worktrees, err := w.ListWorktrees()
+	if err != nil {
+		return nil, err
+	}
+
+	data := sync.NewTemplateData()
+	data.WorktreeName = filepath.Base(wtPath)
+	data.RepoName = w.Name
+
+	target := resolvePath(wtPath)
+	for _, wt := range worktrees {
+		if resolvePath(wt.Path) == target {
+			data.Branch = wt.Branch
+			break
+		}
+	}
+
+	// Only wm add reserves indexes and ports; syncing and checking never
+	// change the registries
+	data.Index = w.worktreeIndex(wtPath)
+	if block, ok := w.Ports(wtPath); ok {
+		data.Ports = block.Ports()
+		data.Port = block.Start
+	}
+	return data, nil
+}
+
+func (w *Workspace) syncOptions() sync.Options {
+	return sync.Options{
+		IgnoreDirs:    w.Config.Scan.IgnoreDirs,
+		RelativeLinks: w.Config.Symlinks.Relative,
+	}
+}
+
+func (w *Workspace) runPostInstall(wtPath, branch string) error {
+	postInstall := w.Config.Tasks.PostInstall
+	if len(postInstall.Commands) == 0 {
 		return nil
 	}
 
+	opts := runner.Options{
+		Shell: w.taskShell(postInstall.Shell),
+		Env:   w.taskEnv(wtPath, branch),
+	}
+	if postInstall.Mode == "background" {
+		return w.startPostInstall(wtPath, opts)
+	}
+
 	w.UI.Print("Running post-install tasks...")
-	isBackground := w.Config.Tasks.PostInstall.Mode == "background"
-	if err := runner.RunCommands(wtPath, cmds, isBackground); err != nil {
+	if err := runner.RunCommands(wtPath, postInstall.Commands, opts); err != nil {
 		return fmt.Errorf("post-install failed: %w", err)
 	}
+	w.UI.Print("Post-install completed.")
+	return nil
+}
 
-	if isBackground {
-		w.UI.Print("Background tasks started.")
-	} else {
-		w.UI.Print("Post-install completed.")
+func (w *Workspace) startPostInstall(wtPath string, opts runner.Options) error {
+	stateDir, err := StateDir(wtPath)
+	if err != nil {
+		return err
 	}
+
+	job := runner.NewJob(PostInstallJob, wtPath, opts.Shell, w.Config.Tasks.PostInstall.Commands)
+	job.Env = opts.Env
+	job.Notify = w.Config.Tasks.PostInstall.Notify
+	if err := runner.StartJob(stateDir, job); err != nil {
+		return fmt.Errorf("post-install failed: %w", err)
+	}
+
+	w.UI.Printf("Background tasks started (pid %d).\n", job.PID)
+	w.UI.Printf("  log: %s\n", runner.LogPath(stateDir, job.Name))
 	return nil
 }
 
+// taskShell returns the shell for a task, falling back to tasks.shell
+func (w *Workspace) taskShell(override string) string {
+	if override != "" {
+		return override
+	}
+	return w.Config.Tasks.Shell
+}
+
+// CurrentWorktree returns the worktree containing the working directory
+func (w *Workspace) CurrentWorktree() (*git.Worktree, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	top, err := git.GetRepoRoot(cwd)
+	if err != nil {
+		return nil, err
+	}
+	return w.FindWorktree(top)
+}
+
+// FindWorktree resolves a worktree by path, directory name or branch
+func (w *Workspace) FindWorktree(target string) (*git.Worktree, error) {
+	worktrees, err := w.ListWorktrees()
+	if err != nil {
+		return nil, err
+	}
+
+	wt := w.findWorktree(worktrees, target)
+	if wt == nil {
+		return nil, fmt.Errorf("worktree '%s' not found", target)
+	}
+	return wt, nil
+}
+
 // RemoveWorktree removes a worktree and optionally its branch
 func (w *Workspace) RemoveWorktree(path string, deleteBranch, force bool) error {
 	worktrees, err := w.ListWorktrees()
@@ -150,7 +630,9 @@ func (w *Workspace) RemoveWorktree(path string, deleteBranch, force bool) error
 		return err
 	}
 
-	target := w.findWorktree(worktrees, path)
+	// Only paths and directory names, so a branch name never removes a
+	// worktree the user did not point at
+	target := findWorktreeByPath(worktrees, path)
 	if target == nil {
 		return fmt.Errorf("worktree '%s' not found", path)
 	}
@@ -177,20 +659,48 @@ func (w *Workspace) RemoveWorktree(path string, deleteBranch, force bool) error
 		}
 	}
 
+	if err := w.runHook(HookPreRemove, target.Path, target.Path, target.Branch); err != nil {
+		return fmt.Errorf("worktree not removed: %w", err)
+	}
+
 	w.UI.Printf("Removing worktree...")
 	if err := git.RemoveWorktree(w.Root, target.Path, force); err != nil {
 		return err
 	}
 	w.UI.Print(" done.")
 
+	if err := w.FreePorts(target.Path); err != nil {
+		w.UI.Printf("Failed to release ports: %v\n", err)
+	}
+	if err := w.freeIndex(target.Path); err != nil {
+		w.UI.Printf("Failed to release index: %v\n", err)
+	}
+
 	if deleteBranch && target.Branch != "" {
 		w.deleteBranch(target.Branch)
 	}
 
-	return nil
+	return w.runHook(HookPostRemove, w.Root, target.Path, target.Branch)
 }
 
 func (w *Workspace) findWorktree(worktrees []git.Worktree, path string) *git.Worktree {
+	if wt := findWorktreeByPath(worktrees, path); wt != nil {
+		return wt
+	}
+
+	// Fall back to the checked-out branch so "feature/x" finds its worktree
+	// even when the directory name differs, with or without branch_prefix
+	prefixed := w.Config.Worktree.BranchPrefix + path
+	for i, wt := range worktrees {
+		if wt.Branch != "" && (wt.Branch == path || wt.Branch == prefixed) {
+			return &worktrees[i]
+		}
+	}
+	return nil
+}
+
+// findWorktreeByPath matches a worktree by path or directory name
+func findWorktreeByPath(worktrees []git.Worktree, path string) *git.Worktree {
 	absPath := resolvePath(path)
 
 	for i, wt := range worktrees {
@@ -1,11 +1,13 @@
 package tests
 
 import (
+	"encoding/json"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func setupTestRepo(t *testing.T) string {
@@ -60,6 +62,47 @@ func buildWM(t *testing.T) string {
 	return tmpBin
 }
 
+// writeConfig writes content as the repository's .wm.yaml.
+func writeConfig(t *testing.T, repoDir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(repoDir, ".wm.yaml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// tryWM runs wm in dir, feeding it stdin, and returns the combined output.
+// Shell integration is disabled so switch prints the path.
+func tryWM(bin, dir, stdin string, args ...string) (string, error) {
+	cmd := exec.Command(bin, args...)
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Env = append(os.Environ(), "WM_CD_FILE=")
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+// runWM is like tryWM but fails the test if wm exits non-zero.
+func runWM(t *testing.T, bin, dir, stdin string, args ...string) string {
+	t.Helper()
+	out, err := tryWM(bin, dir, stdin, args...)
+	if err != nil {
+		t.Fatalf("wm %v failed: %v\n%s", args, err, out)
+	}
+	return out
+}
+
+// runGit runs git in dir and returns its trimmed output.
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
 func TestE2E_ListEmpty(t *testing.T) {
 	wmBin := buildWM(t)
 	repoDir := setupTestRepo(t)
@@ -246,3 +289,1248 @@ func TestE2E_Version(t *testing.T) {
 		t.Errorf("expected version info, got: %s", out)
 	}
 }
+
+func TestE2E_Switch(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+
+	configContent := `version: 1
+worktree:
+  base_dir: "../wm_switch_test"
+`
+	writeConfig(t, repoDir, configContent)
+
+	cmd := exec.Command(wmBin, "add", "feature/switch")
+	cmd.Dir = repoDir
+	cmd.Stdin = strings.NewReader("y\n")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("wm add failed: %v\n%s", err, out)
+	}
+
+	// Without shell integration the path is printed
+	cmd = exec.Command(wmBin, "switch", "feature/switch")
+	cmd.Dir = repoDir
+	cmd.Env = append(os.Environ(), "WM_CD_FILE=")
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("wm switch failed: %v\n%s", err, out)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(string(out)), filepath.Join("wm_switch_test", "feature", "switch")) {
+		t.Errorf("expected worktree path, got: %s", out)
+	}
+
+	// With shell integration the path is handed over through WM_CD_FILE
+	cdFile := filepath.Join(t.TempDir(), "cd")
+	cmd = exec.Command(wmBin, "switch", "feature/switch")
+	cmd.Dir = repoDir
+	cmd.Env = append(os.Environ(), "WM_CD_FILE="+cdFile)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("wm switch failed: %v\n%s", err, out)
+	}
+	content, err := os.ReadFile(cdFile)
+	if err != nil {
+		t.Fatalf("failed to read cd file: %v", err)
+	}
+	if !strings.HasSuffix(string(content), filepath.Join("wm_switch_test", "feature", "switch")) {
+		t.Errorf("expected worktree path in cd file, got: %s", content)
+	}
+}
+
+func TestE2E_BranchLookup(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+
+	configContent := `version: 1
+worktree:
+  base_dir: "../wm_lookup_test"
+  path_template: "{branch_slug}"
+`
+	writeConfig(t, repoDir, configContent)
+
+	if out, err := tryWM(wmBin, repoDir, "y\n", "add", "feature/lookup"); err != nil {
+		t.Fatalf("wm add failed: %v\n%s", err, out)
+	}
+	wtDir := filepath.Join("wm_lookup_test", "feature-lookup")
+
+	// switch finds the worktree by branch although the directory differs
+	out, err := tryWM(wmBin, repoDir, "y\n", "switch", "feature/lookup")
+	if err != nil || !strings.HasSuffix(strings.TrimSpace(out), wtDir) {
+		t.Errorf("expected switch to find the worktree by branch, got: %v\n%s", err, out)
+	}
+
+	// remove only matches paths and directory names
+	if out, err := tryWM(wmBin, repoDir, "y\n", "remove", "-f", "feature/lookup"); err == nil || !strings.Contains(out, "not found") {
+		t.Errorf("expected remove by branch name to fail, got: %v\n%s", err, out)
+	}
+	if out, err := tryWM(wmBin, repoDir, "y\n", "remove", "-f", "feature-lookup"); err != nil {
+		t.Errorf("wm remove by directory name failed: %v\n%s", err, out)
+	}
+}
+
+func TestE2E_BackgroundTasks(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+
+	configContent := `version: 1
+worktree:
+  base_dir: "../wm_bg_test"
+tasks:
+  post_install:
+    mode: background
+    commands:
+      - "echo installing > installed.txt && echo done"
+`
+	writeConfig(t, repoDir, configContent)
+
+	cmd := exec.Command(wmBin, "add", "bg-test")
+	cmd.Dir = repoDir
+	cmd.Stdin = strings.NewReader("y\n")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("wm add failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "Background tasks started") {
+		t.Errorf("expected background start message, got: %s", out)
+	}
+
+	// The detached job records its state under the worktree's git dir
+	statePath := filepath.Join(repoDir, ".git", "worktrees", "bg-test", "wm", "post_install.json")
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		content, _ := os.ReadFile(statePath)
+		if strings.Contains(string(content), `"state": "succeeded"`) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("background job did not succeed, state: %s", content)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	logPath := filepath.Join(repoDir, ".git", "worktrees", "bg-test", "wm", "post_install.log")
+	logContent, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log: %v", err)
+	}
+	if !strings.Contains(string(logContent), "done") {
+		t.Errorf("expected command output in log, got: %s", logContent)
+	}
+}
+
+func TestE2E_LifecycleHooks(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+	hookLog := filepath.Join(t.TempDir(), "hooks.log")
+
+	configContent := `version: 1
+worktree:
+  base_dir: "../wm_hooks_test"
+tasks:
+  pre_add:
+    - 'test "$WM_BRANCH" != vetoed'
+    - 'echo "pre_add $WM_BRANCH $WM_REPO_NAME" >> ` + hookLog + `'
+  post_add:
+    - 'echo "post_add $(basename "$PWD")" >> ` + hookLog + `'
+  pre_remove:
+    commands:
+      - 'echo "$WM_HOOK $(basename "$WM_WORKTREE_PATH")" >> ` + hookLog + `'
+  post_remove:
+    - 'test ! -d "$WM_WORKTREE_PATH" && echo "post_remove gone" >> ` + hookLog + `'
+`
+	writeConfig(t, repoDir, configContent)
+
+	// A failing pre_add hook vetoes creation
+	cmd := exec.Command(wmBin, "add", "vetoed")
+	cmd.Dir = repoDir
+	cmd.Stdin = strings.NewReader("y\n")
+	if out, err := cmd.CombinedOutput(); err == nil {
+		t.Fatalf("expected wm add to fail, got: %s", out)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, "..", "wm_hooks_test", "vetoed")); !os.IsNotExist(err) {
+		t.Error("vetoed worktree should not have been created")
+	}
+
+	cmd = exec.Command(wmBin, "add", "hooked")
+	cmd.Dir = repoDir
+	cmd.Stdin = strings.NewReader("y\n")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("wm add failed: %v\n%s", err, out)
+	}
+
+	cmd = exec.Command(wmBin, "remove", "-f", "hooked")
+	cmd.Dir = repoDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("wm remove failed: %v\n%s", err, out)
+	}
+
+	content, err := os.ReadFile(hookLog)
+	if err != nil {
+		t.Fatalf("failed to read hook log: %v", err)
+	}
+	want := "pre_add hooked " + filepath.Base(repoDir) + "\npost_add hooked\npre_remove hooked\npost_remove gone\n"
+	if string(content) != want {
+		t.Errorf("expected hook log %q, got %q", want, content)
+	}
+}
+
+func TestE2E_AddRollback(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+
+	configContent := `version: 1
+worktree:
+  base_dir: "../wm_rollback_test"
+tasks:
+  post_install:
+    mode: foreground
+    commands:
+      - "exit 1"
+`
+	writeConfig(t, repoDir, configContent)
+
+	branchExists := func(branch string) bool {
+		cmd := exec.Command("git", "show-ref", "--verify", "--quiet", "refs/heads/"+branch)
+		cmd.Dir = repoDir
+		return cmd.Run() == nil
+	}
+	baseDir := filepath.Join(repoDir, "..", "wm_rollback_test")
+
+	// New branch: worktree and branch are rolled back
+	cmd := exec.Command(wmBin, "add", "new-branch")
+	cmd.Dir = repoDir
+	cmd.Stdin = strings.NewReader("y\n")
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected wm add to fail, got: %s", out)
+	}
+	if !strings.Contains(string(out), "Rolling back") {
+		t.Errorf("expected rollback report, got: %s", out)
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, "new-branch")); !os.IsNotExist(err) {
+		t.Error("worktree should have been removed")
+	}
+	if branchExists("new-branch") {
+		t.Error("branch created by wm should have been deleted")
+	}
+
+	// Existing branch: worktree is removed but the branch is kept
+	gitCmd := exec.Command("git", "branch", "existing")
+	gitCmd.Dir = repoDir
+	if out, err := gitCmd.CombinedOutput(); err != nil {
+		t.Fatalf("failed to create branch: %v\n%s", err, out)
+	}
+
+	cmd = exec.Command(wmBin, "add", "existing")
+	cmd.Dir = repoDir
+	if out, err := cmd.CombinedOutput(); err == nil {
+		t.Fatalf("expected wm add to fail, got: %s", out)
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, "existing")); !os.IsNotExist(err) {
+		t.Error("worktree should have been removed")
+	}
+	if !branchExists("existing") {
+		t.Error("pre-existing branch should have been kept")
+	}
+
+	// --keep-on-failure leaves everything in place
+	cmd = exec.Command(wmBin, "add", "--keep-on-failure", "kept")
+	cmd.Dir = repoDir
+	cmd.Stdin = strings.NewReader("y\n")
+	if out, err := cmd.CombinedOutput(); err == nil {
+		t.Fatalf("expected wm add to fail, got: %s", out)
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, "kept")); err != nil {
+		t.Errorf("worktree should have been kept: %v", err)
+	}
+	if !branchExists("kept") {
+		t.Error("branch should have been kept")
+	}
+}
+
+func TestE2E_Sync(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+
+	configContent := `version: 1
+worktree:
+  base_dir: "../wm_resync_test"
+sync:
+  - ".env"
+  - ".env.local"
+`
+	writeConfig(t, repoDir, configContent)
+	if err := os.WriteFile(filepath.Join(repoDir, ".env"), []byte("SECRET=old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, branch := range []string{"one", "two"} {
+		cmd := exec.Command(wmBin, "add", branch)
+		cmd.Dir = repoDir
+		cmd.Stdin = strings.NewReader("y\n")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("wm add failed: %v\n%s", err, out)
+		}
+	}
+
+	// Rotate the secret and push it to every worktree
+	if err := os.WriteFile(filepath.Join(repoDir, ".env"), []byte("SECRET=new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(wmBin, "sync", "--all")
+	cmd.Dir = repoDir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("wm sync failed: %v\n%s", err, out)
+	}
+	if strings.Count(string(out), "  copied ") != 2 || strings.Count(string(out), "skipped-missing") != 2 ||
+		!strings.Contains(string(out), "1 file(s), 10 B copied") {
+		t.Errorf("unexpected sync report: %s", out)
+	}
+
+	for _, branch := range []string{"one", "two"} {
+		content, err := os.ReadFile(filepath.Join(repoDir, "..", "wm_resync_test", branch, ".env"))
+		if err != nil {
+			t.Fatalf("failed to read synced .env: %v", err)
+		}
+		if string(content) != "SECRET=new" {
+			t.Errorf("expected 'SECRET=new' in %s, got '%s'", branch, content)
+		}
+	}
+
+	// Running inside a worktree syncs that worktree from the main checkout
+	cmd = exec.Command(wmBin, "sync")
+	cmd.Dir = filepath.Join(repoDir, "..", "wm_resync_test", "one")
+	out, err = cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("wm sync failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "unchanged") {
+		t.Errorf("expected unchanged report, got: %s", out)
+	}
+}
+
+func TestE2E_Seed(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+
+	files := map[string]string{
+		".gitignore":        "node_modules/\n",
+		"package.json":      `{"name": "seed"}`,
+		"package-lock.json": `{"lockfileVersion": 3}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(repoDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runGit(t, repoDir, "add", ".")
+	runGit(t, repoDir, "commit", "-m", "add package")
+
+	// A branch whose lockfile differs must not get the root's node_modules
+	runGit(t, repoDir, "branch", "upgraded")
+	runGit(t, repoDir, "checkout", "-q", "upgraded")
+	if err := os.WriteFile(filepath.Join(repoDir, "package-lock.json"), []byte(`{"lockfileVersion": 3, "packages": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repoDir, "commit", "-am", "upgrade")
+	runGit(t, repoDir, "checkout", "-q", "-")
+
+	if err := os.MkdirAll(filepath.Join(repoDir, "node_modules", "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "node_modules", "pkg", "index.js"), []byte("module.exports = 1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	configContent := `version: 1
+worktree:
+  base_dir: "../wm_seed_test"
+seed: true
+`
+	writeConfig(t, repoDir, configContent)
+	baseDir := filepath.Join(repoDir, "..", "wm_seed_test")
+
+	cmd := exec.Command(wmBin, "add", "seeded")
+	cmd.Dir = repoDir
+	cmd.Stdin = strings.NewReader("y\n")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("wm add failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "Seeded 1 file(s)") {
+		t.Errorf("expected seed summary, got: %s", out)
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, "seeded", "node_modules", "pkg", "index.js")); err != nil {
+		t.Errorf("expected node_modules to be seeded: %v", err)
+	}
+
+	cmd = exec.Command(wmBin, "add", "upgraded")
+	cmd.Dir = repoDir
+	out, err = cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("wm add failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "Skipping seed: package-lock.json differs") {
+		t.Errorf("expected seed to be skipped, got: %s", out)
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, "upgraded", "node_modules")); !os.IsNotExist(err) {
+		t.Error("node_modules should not be seeded when lockfiles differ")
+	}
+}
+
+func TestE2E_TemplateSync(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+
+	configContent := `version: 1
+worktree:
+  base_dir: "../wm_template_test"
+ports:
+  start: 4000
+  end: 4099
+  block_size: 5
+sync:
+  - src: ".env.tmpl"
+    dst: ".env"
+    mode: template
+`
+	writeConfig(t, repoDir, configContent)
+	tmpl := "PORT={{.Port}}\nCOMPOSE_PROJECT_NAME={{.RepoName}}-{{.WorktreeName}}\nBRANCH={{.Branch}}\nINDEX={{.Index}}\n"
+	if err := os.WriteFile(filepath.Join(repoDir, ".env.tmpl"), []byte(tmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(wmBin, "add", "feature")
+	cmd.Dir = repoDir
+	cmd.Stdin = strings.NewReader("y\n")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("wm add failed: %v\n%s", err, out)
+	}
+
+	content, err := os.ReadFile(filepath.Join(repoDir, "..", "wm_template_test", "feature", ".env"))
+	if err != nil {
+		t.Fatalf("failed to read rendered .env: %v", err)
+	}
+	want := "PORT=4000\nCOMPOSE_PROJECT_NAME=" + filepath.Base(repoDir) + "-feature\nBRANCH=feature\nINDEX=1\n"
+	if string(content) != want {
+		t.Errorf("expected %q, got %q", want, content)
+	}
+
+	// Removing a worktree does not renumber the others
+	secondEnv := filepath.Join(repoDir, "..", "wm_template_test", "second", ".env")
+	runWM(t, wmBin, repoDir, "y\n", "add", "second")
+	runWM(t, wmBin, repoDir, "", "remove", "-f", "feature")
+	if err := os.Remove(secondEnv); err != nil {
+		t.Fatal(err)
+	}
+	runWM(t, wmBin, repoDir, "", "sync", "second")
+	if content, _ := os.ReadFile(secondEnv); !strings.Contains(string(content), "INDEX=2\n") {
+		t.Errorf("expected second to keep index 2, got %q", content)
+	}
+}
+
+func TestE2E_SyncStatusKeepsPorts(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+
+	configContent := `version: 1
+worktree:
+  base_dir: "../wm_keep_ports_test"
+ports:
+  block_size: 2
+sync:
+  - src: ".env.tmpl"
+    dst: ".env"
+    mode: template
+`
+	writeConfig(t, repoDir, configContent)
+	if err := os.WriteFile(filepath.Join(repoDir, ".env.tmpl"), []byte("PORT={{.Port}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runWM(t, wmBin, repoDir, "y\n", "add", "one")
+	// A worktree created without wm has no port block
+	runGit(t, repoDir, "worktree", "add", "-b", "manual", filepath.Join(repoDir, "..", "wm_keep_ports_test", "manual"))
+
+	portsPath := filepath.Join(repoDir, ".git", "wm", "ports.json")
+	before, err := os.ReadFile(portsPath)
+	if err != nil {
+		t.Fatalf("failed to read ports.json: %v", err)
+	}
+
+	runWM(t, wmBin, repoDir, "y\n", "sync", "status", "--all")
+	runWM(t, wmBin, repoDir, "y\n", "sync", "status", "--json", "manual")
+
+	after, err := os.ReadFile(portsPath)
+	if err != nil {
+		t.Fatalf("failed to read ports.json: %v", err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("expected sync status to leave ports.json untouched, got:\n%s\nwas:\n%s", after, before)
+	}
+}
+
+func TestE2E_Ports(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+	baseDir := filepath.Join(repoDir, "..", "wm_ports_test")
+
+	// Ports are only reserved when configured
+	configContent := `version: 1
+worktree:
+  base_dir: "../wm_ports_test"
+tasks:
+  post_add:
+    - 'echo "$WM_PORT_0" > ports.txt'
+`
+	writeConfig(t, repoDir, configContent)
+	runWM(t, wmBin, repoDir, "y\n", "add", "plain")
+	if content, _ := os.ReadFile(filepath.Join(baseDir, "plain", "ports.txt")); strings.TrimSpace(string(content)) != "" {
+		t.Errorf("expected no ports without a ports section, got %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, ".git", "wm", "ports.json")); !os.IsNotExist(err) {
+		t.Errorf("expected no port registry without a ports section: %v", err)
+	}
+	runWM(t, wmBin, repoDir, "", "remove", "-f", "plain")
+
+	configContent = `version: 1
+worktree:
+  base_dir: "../wm_ports_test"
+ports:
+  start: 5000
+  end: 5019
+  block_size: 10
+tasks:
+  post_add:
+    - 'echo "$WM_PORT_0 $WM_PORT_9" > ports.txt'
+`
+	writeConfig(t, repoDir, configContent)
+
+	for _, branch := range []string{"one", "two"} {
+		if out, err := tryWM(wmBin, repoDir, "y\n", "add", branch); err != nil {
+			t.Fatalf("wm add %s failed: %v\n%s", branch, err, out)
+		}
+	}
+
+	content, err := os.ReadFile(filepath.Join(baseDir, "two", "ports.txt"))
+	if err != nil {
+		t.Fatalf("failed to read ports.txt: %v", err)
+	}
+	if strings.TrimSpace(string(content)) != "5010 5019" {
+		t.Errorf("expected hook to see ports 5010-5019, got %q", content)
+	}
+
+	out, err := tryWM(wmBin, repoDir, "y\n", "list")
+	if err != nil {
+		t.Fatalf("wm list failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "5000-5009") || !strings.Contains(out, "5010-5019") {
+		t.Errorf("expected port blocks in list, got: %s", out)
+	}
+
+	// The range is exhausted until a worktree is removed
+	if out, err := tryWM(wmBin, repoDir, "y\n", "add", "three"); err == nil || !strings.Contains(out, "no free block") {
+		t.Fatalf("expected port exhaustion, got: %v\n%s", err, out)
+	}
+	if out, err := tryWM(wmBin, repoDir, "y\n", "remove", "-f", filepath.Join(baseDir, "one")); err != nil {
+		t.Fatalf("wm remove failed: %v\n%s", err, out)
+	}
+	if out, err := tryWM(wmBin, repoDir, "y\n", "add", "three"); err != nil {
+		t.Fatalf("wm add three failed: %v\n%s", err, out)
+	}
+	content, err = os.ReadFile(filepath.Join(baseDir, "three", "ports.txt"))
+	if err != nil {
+		t.Fatalf("failed to read ports.txt: %v", err)
+	}
+	if strings.TrimSpace(string(content)) != "5000 5009" {
+		t.Errorf("expected freed ports to be reused, got %q", content)
+	}
+}
+
+func TestE2E_SyncFrom(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+
+	configContent := `version: 1
+worktree:
+  base_dir: "../wm_syncfrom_test"
+sync:
+  - ".env"
+`
+	writeConfig(t, repoDir, configContent)
+	if err := os.WriteFile(filepath.Join(repoDir, ".env"), []byte("SECRET=old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	baseDir := filepath.Join(repoDir, "..", "wm_syncfrom_test")
+
+	readEnv := func(dir string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, ".env"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	runWM(t, wmBin, repoDir, "y\n", "add", "one")
+	runWM(t, wmBin, repoDir, "y\n", "add", "two")
+	if err := os.WriteFile(filepath.Join(baseDir, "one", ".env"), []byte("SECRET=new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Declining leaves the root alone but still shows the diff
+	out := runWM(t, wmBin, repoDir, "n\n", "sync", "--from", "one")
+	if !strings.Contains(out, "-SECRET=old") || !strings.Contains(out, "+SECRET=new") {
+		t.Errorf("expected diff preview, got: %s", out)
+	}
+	if readEnv(repoDir) != "SECRET=old\n" {
+		t.Error("root .env changed although the sync was declined")
+	}
+
+	runWM(t, wmBin, repoDir, "y\n", "sync", "--from", "one")
+	if readEnv(repoDir) != "SECRET=new\n" {
+		t.Error("expected root .env to be updated")
+	}
+	if readEnv(filepath.Join(baseDir, "two")) != "SECRET=old\n" {
+		t.Error("sibling should only change with --all")
+	}
+
+	runWM(t, wmBin, repoDir, "", "sync", "--from", "one", "--all", "--force")
+	if readEnv(filepath.Join(baseDir, "two")) != "SECRET=new\n" {
+		t.Error("expected sibling .env to be updated with --all")
+	}
+
+	// --to names worktrees only, even one called "root"
+	runWM(t, wmBin, repoDir, "y\n", "add", "root")
+	if err := os.WriteFile(filepath.Join(baseDir, "one", ".env"), []byte("SECRET=newer\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runWM(t, wmBin, repoDir, "", "sync", "--from", "one", "--to", "root", "--force")
+	if readEnv(filepath.Join(baseDir, "root")) != "SECRET=newer\n" {
+		t.Error("expected the worktree named root to be updated")
+	}
+	if readEnv(repoDir) != "SECRET=new\n" {
+		t.Error("expected the repository root to be left alone")
+	}
+}
+
+func TestE2E_SyncConflict(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+
+	configContent := `version: 1
+worktree:
+  base_dir: "../wm_conflict_test"
+sync:
+  - ".env"
+`
+	writeConfig(t, repoDir, configContent)
+	if err := os.WriteFile(filepath.Join(repoDir, ".env"), []byte("SECRET=old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wtEnv := filepath.Join(repoDir, "..", "wm_conflict_test", "one", ".env")
+
+	runWM(t, wmBin, repoDir, "y\n", "add", "one")
+	if err := os.WriteFile(wtEnv, []byte("SECRET=old\nDEBUG=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, ".env"), []byte("SECRET=new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Skipping keeps the local edit
+	out := runWM(t, wmBin, repoDir, "s\n", "sync", "one")
+	if !strings.Contains(out, "-DEBUG=1") || !strings.Contains(out, "conflict") {
+		t.Errorf("expected conflict with diff, got: %s", out)
+	}
+	if data, _ := os.ReadFile(wtEnv); string(data) != "SECRET=old\nDEBUG=1\n" {
+		t.Errorf("local edit was lost: %q", data)
+	}
+
+	// Backing up moves the edit aside and syncs the new content
+	out = runWM(t, wmBin, repoDir, "b\n", "sync", "one")
+	if !strings.Contains(out, "local copy saved as") {
+		t.Errorf("expected backup report, got: %s", out)
+	}
+	if data, _ := os.ReadFile(wtEnv); string(data) != "SECRET=new\n" {
+		t.Errorf("expected synced content, got %q", data)
+	}
+	backups, _ := filepath.Glob(wtEnv + ".wm-backup-*")
+	if len(backups) != 1 {
+		t.Fatalf("expected one backup, got %v", backups)
+	}
+	if data, _ := os.ReadFile(backups[0]); string(data) != "SECRET=old\nDEBUG=1\n" {
+		t.Errorf("unexpected backup content %q", data)
+	}
+}
+
+func TestE2E_SyncStatus(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+
+	configContent := `version: 1
+worktree:
+  base_dir: "../wm_status_test"
+sync:
+  - ".env"
+  - ".env.local"
+  - src: "shared.txt"
+    mode: symlink
+`
+	writeConfig(t, repoDir, configContent)
+	for name, content := range map[string]string{".env": "A=1\n", ".env.local": "B=1\n", "shared.txt": "shared\n"} {
+		if err := os.WriteFile(filepath.Join(repoDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wtDir := filepath.Join(repoDir, "..", "wm_status_test", "one")
+
+	runWM(t, wmBin, repoDir, "y\n", "add", "one")
+	out := runWM(t, wmBin, repoDir, "", "sync", "status", "one")
+	if strings.Count(out, "in-sync ") != 3 || !strings.Contains(out, "3 in-sync") {
+		t.Errorf("expected everything in sync, got: %s", out)
+	}
+
+	if err := os.WriteFile(filepath.Join(repoDir, ".env"), []byte("A=2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wtDir, ".env.local"), []byte("B=2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(repoDir, "shared.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	out = runWM(t, wmBin, repoDir, "", "sync", "status", "one")
+	for _, want := range []string{"1 stale", "1 modified", "1 dangling"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in status, got: %s", want, out)
+		}
+	}
+
+	// Checking never writes to the worktree
+	if data, _ := os.ReadFile(filepath.Join(wtDir, ".env")); string(data) != "A=1\n" {
+		t.Errorf("status modified .env: %q", data)
+	}
+}
+
+func TestE2E_Doctor(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+
+	configContent := `version: 1
+worktree:
+  base_dir: "../wm_doctor_test"
+symlinks:
+  relative: true
+sync:
+  - src: ".env"
+    mode: symlink
+`
+	writeConfig(t, repoDir, configContent)
+	if err := os.WriteFile(filepath.Join(repoDir, ".env"), []byte("A=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(repoDir, "..", "wm_doctor_test", "one", ".env")
+
+	runWM(t, wmBin, repoDir, "y\n", "add", "one")
+	if target, err := os.Readlink(link); err != nil || filepath.IsAbs(target) {
+		t.Fatalf("expected a relative symlink, got %q, %v", target, err)
+	}
+
+	out := runWM(t, wmBin, repoDir, "", "doctor")
+	if !strings.Contains(out, "No dangling sync symlinks") {
+		t.Errorf("expected a clean report, got: %s", out)
+	}
+
+	// A link left pointing at an old location of the repository
+	if err := os.Remove(link); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(repoDir, "..", "old-location", ".env"), link); err != nil {
+		t.Fatal(err)
+	}
+
+	out = runWM(t, wmBin, repoDir, "n\n", "doctor")
+	if !strings.Contains(out, "dangling") || !strings.Contains(out, "Aborted") {
+		t.Errorf("expected the dangling link to be reported, got: %s", out)
+	}
+
+	out = runWM(t, wmBin, repoDir, "", "doctor", "--force")
+	if !strings.Contains(out, "relinked") {
+		t.Errorf("expected the link to be repaired, got: %s", out)
+	}
+	if data, err := os.ReadFile(link); err != nil || string(data) != "A=1\n" {
+		t.Errorf("expected repaired link to reach .env, got %q, %v", data, err)
+	}
+	if target, _ := os.Readlink(link); filepath.IsAbs(target) {
+		t.Errorf("expected repaired link to stay relative, got %s", target)
+	}
+}
+
+func TestE2E_AddRemoteBranch(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+
+	configContent := `version: 1
+worktree:
+  base_dir: "../wm_remote_test"
+`
+	writeConfig(t, repoDir, configContent)
+
+	// A branch pushed to origin and then deleted locally
+	remoteDir := filepath.Join(t.TempDir(), "origin.git")
+	runGit(t, repoDir, "init", "--bare", remoteDir)
+	runGit(t, repoDir, "remote", "add", "origin", remoteDir)
+	runGit(t, repoDir, "checkout", "-b", "feature-x")
+	runGit(t, repoDir, "commit", "--allow-empty", "-m", "remote work")
+	runGit(t, repoDir, "push", "origin", "feature-x")
+	runGit(t, repoDir, "checkout", "-")
+	runGit(t, repoDir, "branch", "-D", "feature-x")
+
+	// No confirmation is needed for a branch that exists on the remote
+	out := runWM(t, wmBin, repoDir, "", "add", "feature-x")
+	if !strings.Contains(out, "found as origin/feature-x") || !strings.Contains(out, "based on origin/feature-x (") {
+		t.Errorf("expected remote branch messages, got: %s", out)
+	}
+	if upstream := runGit(t, repoDir, "rev-parse", "--abbrev-ref", "feature-x@{upstream}"); upstream != "origin/feature-x" {
+		t.Errorf("expected feature-x to track origin/feature-x, got %s", upstream)
+	}
+
+	// A branch pushed from another clone is only found after fetching
+	cloneDir := filepath.Join(t.TempDir(), "clone")
+	runGit(t, repoDir, "clone", remoteDir, cloneDir)
+	runGit(t, cloneDir, "config", "user.email", "test@test.com")
+	runGit(t, cloneDir, "config", "user.name", "Test")
+	runGit(t, cloneDir, "checkout", "-b", "feature-y")
+	runGit(t, cloneDir, "commit", "--allow-empty", "-m", "other work")
+	runGit(t, cloneDir, "push", "origin", "feature-y")
+
+	out = runWM(t, wmBin, repoDir, "n\n", "add", "feature-y")
+	if !strings.Contains(out, "does not exist locally or on origin") || !strings.Contains(out, "Aborted") {
+		t.Errorf("expected confirmation for an unknown branch, got: %s", out)
+	}
+	out = runWM(t, wmBin, repoDir, "", "add", "--fetch", "feature-y")
+	if !strings.Contains(out, "Fetching origin") || !strings.Contains(out, "found as origin/feature-y") {
+		t.Errorf("expected branch to be found after fetching, got: %s", out)
+	}
+	if upstream := runGit(t, repoDir, "rev-parse", "--abbrev-ref", "feature-y@{upstream}"); upstream != "origin/feature-y" {
+		t.Errorf("expected feature-y to track origin/feature-y, got %s", upstream)
+	}
+}
+
+func TestE2E_AddFromBase(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+
+	readBase := func(wtPath string) map[string]string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(runGit(t, wtPath, "rev-parse", "--absolute-git-dir"), "wm", "base.json"))
+		if err != nil {
+			t.Fatalf("expected a recorded base: %v", err)
+		}
+		var base map[string]string
+		if err := json.Unmarshal(data, &base); err != nil {
+			t.Fatal(err)
+		}
+		return base
+	}
+
+	// A release branch the main worktree is not on
+	runGit(t, repoDir, "branch", "release")
+	runGit(t, repoDir, "commit", "--allow-empty", "-m", "main moves on")
+	releaseCommit := runGit(t, repoDir, "rev-parse", "release")
+
+	out := runWM(t, wmBin, repoDir, "y\n", "add", "fix", "--from", "release")
+	if !strings.Contains(out, "Create it from release?") || !strings.Contains(out, "based on release ("+releaseCommit[:7]+")") {
+		t.Errorf("expected base messages, got: %s", out)
+	}
+	fixPath := filepath.Join(repoDir, "..", "wm_"+filepath.Base(repoDir), "fix")
+	if head := runGit(t, fixPath, "rev-parse", "HEAD"); head != releaseCommit {
+		t.Errorf("expected fix to start at release %s, got %s", releaseCommit, head)
+	}
+	if base := readBase(fixPath); base["ref"] != "release" || base["commit"] != releaseCommit {
+		t.Errorf("unexpected recorded base: %v", base)
+	}
+
+	// Unknown refs are rejected before anything is created
+	if out, err := tryWM(wmBin, repoDir, "y\n", "add", "typo", "--from", "no-such-ref"); err == nil || !strings.Contains(out, "unknown ref no-such-ref") {
+		t.Errorf("expected unknown ref error, got %v: %s", err, out)
+	}
+	if out, err := tryWM(wmBin, repoDir, "", "add", "fix", "--from", "release"); err == nil || !strings.Contains(out, "already exists") {
+		t.Errorf("expected --from to be rejected for an existing branch, got %v: %s", err, out)
+	}
+
+	// worktree.default_base applies when --from is not given
+	configContent := "version: 1\nworktree:\n  default_base: release\n"
+	writeConfig(t, repoDir, configContent)
+	runWM(t, wmBin, repoDir, "y\n", "add", "other")
+	otherPath := filepath.Join(repoDir, "..", "wm_"+filepath.Base(repoDir), "other")
+	if head := runGit(t, otherPath, "rev-parse", "HEAD"); head != releaseCommit {
+		t.Errorf("expected other to start at release %s, got %s", releaseCommit, head)
+	}
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "other@{upstream}")
+	cmd.Dir = repoDir
+	if out, err := cmd.Output(); err == nil {
+		t.Errorf("expected other to have no upstream, got %s", out)
+	}
+}
+
+func TestE2E_WorktreePathTemplate(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+	repoName := filepath.Base(repoDir)
+	baseDir := filepath.Join(repoDir, "..", "wm_paths_test")
+
+	configure := func(onCollision string) {
+		t.Helper()
+		configContent := `version: 1
+worktree:
+  base_dir: "../wm_paths_test"
+  path_template: "{repo}-{branch_slug}"
+  branch_prefix: "alice/"
+  slug:
+    lowercase: true
+  on_collision: ` + onCollision + `
+`
+		writeConfig(t, repoDir, configContent)
+	}
+
+	configure("error")
+	out, err := tryWM(wmBin, repoDir, "y\n", "add", "Login@Page")
+	if err != nil {
+		t.Fatalf("wm add failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Using branch 'alice/Login@Page'") {
+		t.Errorf("expected branch prefix to be applied, got: %s", out)
+	}
+	slugDir := filepath.Join(baseDir, repoName+"-alice-login-page")
+	if _, err := os.Stat(slugDir); err != nil {
+		t.Fatalf("expected worktree at %s: %v\n%s", slugDir, err, out)
+	}
+
+	// The branch is found with or without the prefix
+	if out, err := tryWM(wmBin, repoDir, "y\n", "tasks", "Login@Page"); err != nil {
+		t.Errorf("expected worktree to be found by its unprefixed name: %v\n%s", err, out)
+	}
+
+	// A different branch with the same slug collides
+	out, err = tryWM(wmBin, repoDir, "y\n", "add", "login-page")
+	if err == nil || !strings.Contains(out, "already exists") {
+		t.Errorf("expected a collision error, got %v: %s", err, out)
+	}
+
+	configure("suffix")
+	out, err = tryWM(wmBin, repoDir, "y\n", "add", "login-page")
+	if err != nil {
+		t.Fatalf("wm add failed: %v\n%s", err, out)
+	}
+	if _, err := os.Stat(slugDir + "-2"); err != nil {
+		t.Errorf("expected suffixed worktree at %s-2: %v\n%s", slugDir, err, out)
+	}
+}
+
+func TestE2E_Status(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+
+	configContent := `version: 1
+worktree:
+  base_dir: "../wm_dashboard_test"
+`
+	writeConfig(t, repoDir, configContent)
+
+	runWM(t, wmBin, repoDir, "y\n", "add", "one")
+	wtDir := filepath.Join(repoDir, "..", "wm_dashboard_test", "one")
+	runGit(t, wtDir, "commit", "--allow-empty", "-m", "unpushed work")
+	if err := os.WriteFile(filepath.Join(wtDir, "scratch.txt"), []byte("wip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repoDir, "worktree", "lock", wtDir)
+
+	var line string
+	for _, l := range strings.Split(runWM(t, wmBin, repoDir, "", "status"), "\n") {
+		if strings.Contains(l, "wm_dashboard_test") {
+			line = l
+		}
+	}
+	for _, want := range []string{"(locked)", "one", "1 untracked", "none", "+1/-0", "unpushed work (just now)"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("expected %q in status line, got: %s", want, line)
+		}
+	}
+}
+
+func TestE2E_JSONOutput(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+
+	configContent := `version: 1
+worktree:
+  base_dir: "../wm_json_test"
+ports:
+  block_size: 2
+sync:
+  - ".env"
+`
+	writeConfig(t, repoDir, configContent)
+	if err := os.WriteFile(filepath.Join(repoDir, ".env"), []byte("A=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runWM(t, wmBin, repoDir, "y\n", "add", "one")
+	wtDir := filepath.Join(repoDir, "..", "wm_json_test", "one")
+	runGit(t, repoDir, "worktree", "lock", "--reason", "testing", wtDir)
+
+	var list struct {
+		Version   int `json:"version"`
+		Worktrees []struct {
+			Path       string `json:"path"`
+			Branch     string `json:"branch"`
+			Locked     bool   `json:"locked"`
+			LockReason string `json:"lock_reason"`
+			Ports      []int  `json:"ports"`
+		} `json:"worktrees"`
+	}
+	out := runWM(t, wmBin, repoDir, "", "list", "--json")
+	if err := json.Unmarshal([]byte(out), &list); err != nil {
+		t.Fatalf("list --json is not valid JSON: %v\n%s", err, out)
+	}
+	if list.Version != 1 || len(list.Worktrees) != 2 {
+		t.Fatalf("unexpected list output: %s", out)
+	}
+	if wt := list.Worktrees[1]; wt.Branch != "one" || !wt.Locked || wt.LockReason != "testing" || len(wt.Ports) == 0 {
+		t.Errorf("unexpected worktree entry %+v", wt)
+	}
+
+	out = runWM(t, wmBin, repoDir, "", "list", "--format", "{{.Branch}}:{{.Locked}}")
+	if !strings.Contains(out, "one:true\n") {
+		t.Errorf("expected formatted line, got: %s", out)
+	}
+
+	var status struct {
+		Worktrees []struct {
+			Branch    string `json:"branch"`
+			Dirty     bool   `json:"dirty"`
+			Untracked int    `json:"untracked"`
+			Base      string `json:"base"`
+		} `json:"worktrees"`
+	}
+	out = runWM(t, wmBin, repoDir, "", "status", "--json")
+	if err := json.Unmarshal([]byte(out), &status); err != nil {
+		t.Fatalf("status --json is not valid JSON: %v\n%s", err, out)
+	}
+	if len(status.Worktrees) != 2 || status.Worktrees[1].Base == "" || status.Worktrees[1].Untracked != 1 {
+		t.Errorf("unexpected status output: %s", out)
+	}
+
+	out = runWM(t, wmBin, repoDir, "", "sync", "status", "one", "--format", "{{.State}} {{.Dst}}")
+	if strings.TrimSpace(out) != "in-sync .env" {
+		t.Errorf("expected formatted sync status, got: %s", out)
+	}
+
+	cmd := exec.Command(wmBin, "list", "--json", "--format", "{{.Path}}")
+	cmd.Dir = repoDir
+	if err := cmd.Run(); err == nil {
+		t.Error("expected --json and --format to be mutually exclusive")
+	}
+}
+
+func TestE2E_StatusBase(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+
+	configContent := `version: 1
+worktree:
+  base_dir: "../wm_status_base_test"
+`
+	writeConfig(t, repoDir, configContent)
+
+	statusLine := func(name string) string {
+		t.Helper()
+		for _, line := range strings.Split(runWM(t, wmBin, repoDir, "", "status"), "\n") {
+			if strings.Contains(line, filepath.Join("wm_status_base_test", name)) {
+				return line
+			}
+		}
+		t.Fatalf("no status line for %s", name)
+		return ""
+	}
+
+	// A detached main worktree is compared by commit, not as "HEAD" inside
+	// each worktree
+	runGit(t, repoDir, "checkout", "--detach")
+	runWM(t, wmBin, repoDir, "y\n", "add", "one")
+	runGit(t, repoDir, "commit", "--allow-empty", "-m", "main moved on")
+	runGit(t, repoDir, "commit", "--allow-empty", "-m", "and again")
+	if line := statusLine("one"); !strings.Contains(line, "+0/-2") {
+		t.Errorf("expected one to be 2 behind the detached HEAD, got: %s", line)
+	}
+
+	// A worktree created --from another ref is compared against that ref
+	runGit(t, repoDir, "branch", "release")
+	runWM(t, wmBin, repoDir, "y\n", "add", "two", "--from", "release")
+	runGit(t, repoDir, "checkout", "release")
+	runGit(t, repoDir, "commit", "--allow-empty", "-m", "release fix")
+	runGit(t, repoDir, "checkout", "--detach")
+	runGit(t, repoDir, "commit", "--allow-empty", "-m", "unrelated")
+	if line := statusLine("two"); !strings.Contains(line, "release +0/-1") {
+		t.Errorf("expected two to be compared against release, got: %s", line)
+	}
+}
+
+func TestE2E_InvalidConfigFailsAdd(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+
+	// The pre_add veto must not be skipped because a sync item is invalid
+	configContent := `version: 1
+worktree:
+  base_dir: "../wm_invalid_test"
+tasks:
+  pre_add:
+    - "exit 1"
+sync:
+  - src: ".env"
+    on_conflict: backups
+`
+	writeConfig(t, repoDir, configContent)
+
+	out, err := tryWM(wmBin, repoDir, "y\n", "add", "feature")
+	if err == nil || !strings.Contains(out, `unknown on_conflict policy "backups"`) {
+		t.Errorf("expected wm add to reject the config, got %v: %s", err, out)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, "..", "wm_invalid_test", "feature")); !os.IsNotExist(err) {
+		t.Error("worktree should not have been created")
+	}
+}
+
+func TestE2E_InvalidConfig(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{
+			name:   "on_conflict",
+			config: "sync:\n  - src: .env\n    on_conflict: backups\n",
+			want:   `sync item 0: unknown on_conflict policy "backups"`,
+		},
+		{
+			name:   "mode",
+			config: "sync:\n  - src: .env\n    mode: softlink\n",
+			want:   `sync item 0: unknown mode "softlink"`,
+		},
+		{
+			name:   "fallback",
+			config: "sync:\n  - src: .env\n    mode: reflink\n    fallback: skip\n",
+			want:   `sync item 0: unknown fallback "skip"`,
+		},
+		{
+			name:   "untracked_ignored with src",
+			config: "sync:\n  - src: .env\n    untracked_ignored: true\n",
+			want:   "sync item 0: src cannot be combined with untracked_ignored",
+		},
+		{
+			name:   "relative without symlink",
+			config: "sync:\n  - src: .env\n    relative: true\n",
+			want:   "sync item 0: relative only applies to mode: symlink",
+		},
+		{
+			name:   "path_template",
+			config: "worktree:\n  path_template: \"{user}-{branch}\"\n",
+			want:   "worktree.path_template: unknown placeholder {user}",
+		},
+		{
+			name:   "on_collision",
+			config: "worktree:\n  on_collision: overwrite\n",
+			want:   `worktree.on_collision: unknown policy "overwrite"`,
+		},
+		{
+			name:   "ports block_size",
+			config: "ports:\n  block_size: 0\n",
+			want:   "ports.block_size must be positive",
+		},
+		{
+			name:   "ports range",
+			config: "ports:\n  start: 4000\n  end: 3000\n",
+			want:   "ports: a block of 10 does not fit in 4000-3000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeConfig(t, repoDir, "version: 1\n"+tt.config)
+			for _, args := range [][]string{{"add", "feature"}, {"sync", "--all"}} {
+				out, err := tryWM(wmBin, repoDir, "y\n", args...)
+				if err == nil || !strings.Contains(out, tt.want) {
+					t.Errorf("expected wm %v to fail with %q, got %v: %s", args, tt.want, err, out)
+				}
+			}
+		})
+	}
+}
+
+func TestE2E_MissingWorktree(t *testing.T) {
+	wmBin := buildWM(t)
+	repoDir := setupTestRepo(t)
+
+	configContent := `version: 1
+worktree:
+  base_dir: "../wm_missing_test"
+sync:
+  - ".env"
+`
+	writeConfig(t, repoDir, configContent)
+	if err := os.WriteFile(filepath.Join(repoDir, ".env"), []byte("A=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	baseDir := filepath.Join(repoDir, "..", "wm_missing_test")
+
+	for _, branch := range []string{"broken", "gone", "one"} {
+		runWM(t, wmBin, repoDir, "y\n", "add", branch)
+	}
+	if err := os.RemoveAll(filepath.Join(baseDir, "gone")); err != nil {
+		t.Fatal(err)
+	}
+
+	out := runWM(t, wmBin, repoDir, "", "tasks")
+	if !strings.Contains(out, "missing; run 'git worktree prune'") {
+		t.Errorf("expected the deleted worktree to be reported, got: %s", out)
+	}
+
+	out = runWM(t, wmBin, repoDir, "", "sync", "status", "--all")
+	if !strings.Contains(out, "missing; run 'git worktree prune'") || strings.Count(out, "1 in-sync") != 2 {
+		t.Errorf("expected the deleted worktree to be reported among the others, got: %s", out)
+	}
+	out = runWM(t, wmBin, repoDir, "", "sync", "status", "--all", "--json")
+	if !strings.Contains(out, `"error": "missing; run 'git worktree prune'"`) {
+		t.Errorf("expected the deleted worktree in the JSON output, got: %s", out)
+	}
+
+	// A worktree that fails to sync does not stop the others
+	brokenEnv := filepath.Join(baseDir, "broken", ".env")
+	if err := os.Remove(brokenEnv); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(brokenEnv, "nested"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, ".env"), []byte("A=2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := tryWM(wmBin, repoDir, "", "sync", "--all")
+	if err == nil || !strings.Contains(out, "failed to sync "+filepath.Join(baseDir, "broken")) {
+		t.Errorf("expected the broken worktree to fail, got %v: %s", err, out)
+	}
+	if !strings.Contains(out, "Warning: skipping") {
+		t.Errorf("expected the deleted worktree to be skipped, got: %s", out)
+	}
+	if data, _ := os.ReadFile(filepath.Join(baseDir, "one", ".env")); string(data) != "A=2\n" {
+		t.Errorf("expected one to be synced after the failure, got %q", data)
+	}
+}